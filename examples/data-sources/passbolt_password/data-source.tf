@@ -7,3 +7,13 @@ output "my_secret_password" {
   # The value will still be hidden, as it's classified as a `sensative` string.
   value = data.passbolt_password.my_secret.password
 }
+
+# Looks up a secret by its name within a folder
+data "passbolt_password" "database" {
+  name        = "postgres"
+  folder_path = "Platform/Databases"
+}
+
+output "database_secret_id" {
+  value = data.passbolt_password.database.id
+}
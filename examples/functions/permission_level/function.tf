@@ -0,0 +1,9 @@
+# Convert a named permission level to its numeric Passbolt permission type
+output "owner_type" {
+  value = provider::passbolt::permission_level("owner") # "15"
+}
+
+# Convert a numeric Passbolt permission type to its name
+output "read_name" {
+  value = provider::passbolt::permission_level("1") # "read"
+}
@@ -5,7 +5,41 @@ resource "passbolt_folder" "basic" {
 
 # Full Passbolt Folder Configuration
 resource "passbolt_folder" "full" {
-  name          = "My Folder"
-  personal      = true
-  folder_parent = "Parent Folder"
+  name             = "My Folder"
+  folder_parent_id = passbolt_folder.basic.id
+}
+
+# Passbolt Folder created from a path, missing parent folders are created as well
+resource "passbolt_folder" "env" {
+  path = "Platform/Team/Env"
+}
+
+# Manage a Passbolt Folder which already exists
+resource "passbolt_folder" "existing" {
+  name        = "Existing Folder"
+  on_conflict = "adopt"
+}
+
+# Passbolt Folder whose contents are moved to another folder when it is destroyed
+resource "passbolt_folder" "temporary" {
+  name      = "Temporary"
+  on_delete = "move_to:Platform/Archive"
+}
+
+# Passbolt Folder shared with a team
+resource "passbolt_folder" "team" {
+  path = "Platform/Team"
+
+  permissions = [
+    {
+      aro        = "User"
+      aro_id     = var.provider_user_id
+      permission = "owner"
+    },
+    {
+      aro        = "Group"
+      aro_id     = passbolt_group.team.id
+      permission = "update"
+    },
+  ]
 }
@@ -0,0 +1,17 @@
+# Manage the complete access list of a folder
+resource "passbolt_folder_permissions" "team" {
+  folder_id = passbolt_folder.basic.id
+
+  permissions = [
+    {
+      aro        = "User"
+      aro_id     = "5f8642a0-f3e3-403b-b666-8cda965fbad6"
+      permission = "owner"
+    },
+    {
+      aro        = "Group"
+      aro_id     = passbolt_group.grp.id
+      permission = "update"
+    },
+  ]
+}
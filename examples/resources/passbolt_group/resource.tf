@@ -6,5 +6,15 @@ resource "passbolt_group" "grp" {
       user_id  = "5f8642a0-f3e3-403b-b666-8cda965fbad6"
       is_admin = true
     },
+    {
+      username = "jane.doe@example.com"
+    },
   ]
+
+  # Hand everything the group solely owns over to the provider user before
+  # the group is deleted.
+  transfer_ownership_to = {
+    type = "User"
+    id   = var.provider_user_id
+  }
 }
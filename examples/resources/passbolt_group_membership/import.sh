@@ -0,0 +1,2 @@
+# Group memberships are imported by <group_id>/<user_id>
+terraform import passbolt_group_membership.ci 2ae2a66a-12a5-4f2b-9c37-2d7b4a3a1f23/5f8642a0-f3e3-403b-b666-8cda965fbad6
@@ -0,0 +1,12 @@
+# Add a service account to a group managed elsewhere
+resource "passbolt_group_membership" "ci" {
+  group_id = var.shared_group_id
+  user_id  = passbolt_user.ci.id
+}
+
+# Add a group manager
+resource "passbolt_group_membership" "lead" {
+  group_id   = var.shared_group_id
+  user_id    = passbolt_user.lead.id
+  is_manager = true
+}
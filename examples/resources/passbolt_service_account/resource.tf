@@ -0,0 +1,18 @@
+# The first apply creates the user and its key pair. Once the invitation
+# email arrives, set invite_url to complete the setup.
+resource "passbolt_service_account" "ci" {
+  username   = "ci@example.com"
+  firstname  = "CI"
+  lastname   = "Bot"
+  invite_url = var.ci_invite_url
+}
+
+output "ci_private_key" {
+  value     = passbolt_service_account.ci.private_key
+  sensitive = true
+}
+
+output "ci_passphrase" {
+  value     = passbolt_service_account.ci.passphrase
+  sensitive = true
+}
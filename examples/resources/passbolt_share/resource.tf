@@ -3,7 +3,7 @@ resource "passbolt_share" "share-folder-with-user" {
   name               = "folder-name"
   share_target_type  = "User"
   share_target_value = "test@user.com"
-  share_permission   = "1"
+  share_permission   = "read"
 }
 
 # Share Passbolt folder with group (update)
@@ -11,7 +11,7 @@ resource "passbolt_share" "share-folder-with-group" {
   name               = "shared-folder-name"
   share_target_type  = "Group"
   share_target_value = "shared-group"
-  share_permission   = "7"
+  share_permission   = "update"
 }
 
 # Un-Share Passbolt folder from group (delete share)
@@ -21,3 +21,29 @@ resource "passbolt_share" "share-folder-with-group" {
   share_target_value = "shared-group"
   share_permission   = "-1"
 }
+
+# Share a single Passbolt password with a group (read)
+resource "passbolt_share" "share-password-with-group" {
+  name               = "password-name"
+  type               = "resource"
+  share_target_type  = "Group"
+  share_target_value = "shared-group"
+  share_permission   = "1"
+}
+
+# Share a folder with a group, both referenced by ID
+resource "passbolt_share" "share-folder-by-id" {
+  folder_id         = passbolt_folder.basic.id
+  share_target_type = "Group"
+  aro_id            = passbolt_group.grp.id
+  share_permission  = "7"
+}
+
+# Share a folder and everything inside it with a group
+resource "passbolt_share" "share-folder-recursively" {
+  folder_id         = passbolt_folder.basic.id
+  share_target_type = "Group"
+  aro_id            = passbolt_group.grp.id
+  share_permission  = "read"
+  propagate         = true
+}
@@ -0,0 +1,5 @@
+# Users are imported by their ID
+terraform import passbolt_user.jane 5f8642a0-f3e3-403b-b666-8cda965fbad6
+
+# or by their username
+terraform import passbolt_user.jane jane@example.com
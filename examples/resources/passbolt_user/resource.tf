@@ -0,0 +1,6 @@
+resource "passbolt_user" "jane" {
+  username  = "jane.doe@example.com"
+  firstname = "Jane"
+  lastname  = "Doe"
+  role      = "admin"
+}
@@ -3,6 +3,7 @@ module terraform-provider-passbolt
 go 1.23.0
 
 require (
+	github.com/ProtonMail/gopenpgp/v2 v2.8.3
 	github.com/hashicorp/terraform-plugin-framework v1.6.1
 	github.com/passbolt/go-passbolt v0.7.2
 )
@@ -16,7 +17,6 @@ require (
 require (
 	github.com/ProtonMail/go-crypto v1.1.6 // indirect
 	github.com/ProtonMail/go-mime v0.0.0-20230322103455-7d82a3887f2f // indirect
-	github.com/ProtonMail/gopenpgp/v2 v2.8.3 // indirect
 	github.com/cloudflare/circl v1.6.0 // indirect
 	github.com/fatih/color v1.16.0 // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
@@ -0,0 +1,276 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+)
+
+// folderPathSeparator separates the folder names of a folder path.
+const folderPathSeparator = "/"
+
+// createdFoldersKey is the private state key holding the IDs of the
+// intermediate folders created for a folder path, in creation order.
+const createdFoldersKey = "created_folders"
+
+// pathConfiguredKey is the private state key recording whether path was set in
+// the configuration, so Read only looks up the full path if it is managed.
+const pathConfiguredKey = "path_configured"
+
+// splitFolderPath splits a folder path such as Platform/Team/Env into its folder names.
+func splitFolderPath(folderPath string) ([]string, error) {
+	names := strings.Split(folderPath, folderPathSeparator)
+	for _, el := range names {
+		if strings.TrimSpace(el) == "" {
+			return nil, fmt.Errorf("invalid folder path %q, folder names must not be empty", folderPath)
+		}
+	}
+	return names, nil
+}
+
+// findChildFolder returns the folder of the given name directly below parentID,
+// or nil if there is none. An empty parentID refers to the root.
+func findChildFolder(folders []api.Folder, parentID string, name string) (*api.Folder, error) {
+	var found *api.Folder
+	for i := range folders {
+		if folders[i].Name != name || folders[i].FolderParentID != parentID {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("found multiple folders named %q in the same parent folder", name)
+		}
+		found = &folders[i]
+	}
+	return found, nil
+}
+
+// folderPathOf returns the path of the folder with the given ID and the IDs
+// of all folders along that path, keyed by their own path.
+func folderPathOf(folders []api.Folder, folderID string) (string, map[string]string) {
+	byID := make(map[string]api.Folder, len(folders))
+	for _, el := range folders {
+		byID[el.ID] = el
+	}
+
+	chain := make([]api.Folder, 0)
+	for id := folderID; id != ""; {
+		folder, ok := byID[id]
+		if !ok {
+			// The parent is not visible to the acting user.
+			break
+		}
+		chain = append([]api.Folder{folder}, chain...)
+		id = folder.FolderParentID
+		if len(chain) > len(folders) {
+			break
+		}
+	}
+
+	names := make([]string, 0, len(chain))
+	idsByPath := make(map[string]string, len(chain))
+	for _, el := range chain {
+		names = append(names, el.Name)
+		idsByPath[strings.Join(names, folderPathSeparator)] = el.ID
+	}
+	return strings.Join(names, folderPathSeparator), idsByPath
+}
+
+// folderIDByPath returns the ID of the folder at the path of the given folder
+// names, or an error if it does not exist or is ambiguous.
+func folderIDByPath(folders []api.Folder, names []string) (string, error) {
+	parentID := ""
+	for i, name := range names {
+		folder, err := findChildFolder(folders, parentID, name)
+		if err != nil {
+			return "", err
+		}
+		if folder == nil {
+			return "", fmt.Errorf("folder %q not found", strings.Join(names[:i+1], folderPathSeparator))
+		}
+		parentID = folder.ID
+	}
+	return parentID, nil
+}
+
+// ensureFolderPath returns the ID of the folder at the given path, creating
+// any missing folders along the way. It also returns the IDs of the created
+// folders in creation order.
+func ensureFolderPath(ctx context.Context, client *api.Client, names []string) (string, []string, error) {
+	created := make([]string, 0)
+	if len(names) == 0 {
+		return "", created, nil
+	}
+
+	folders, err := client.GetFolders(ctx, nil)
+	if err != nil {
+		return "", created, fmt.Errorf("failed to get folders: %w", err)
+	}
+
+	parentID := ""
+	for i, name := range names {
+		existing, err := findChildFolder(folders, parentID, name)
+		if err != nil {
+			return "", created, err
+		}
+		if existing != nil {
+			parentID = existing.ID
+			continue
+		}
+
+		folder, err := client.CreateFolder(ctx, api.Folder{Name: name, FolderParentID: parentID})
+		if err != nil {
+			return "", created, fmt.Errorf("failed to create folder %q: %w", strings.Join(names[:i+1], folderPathSeparator), err)
+		}
+		created = append(created, folder.ID)
+		folders = append(folders, *folder)
+		parentID = folder.ID
+	}
+	return parentID, created, nil
+}
+
+// deleteEmptyFolders deletes the given folders, last one first, as long as they
+// contain neither folders nor resources. Folders which are not empty or already
+// gone are kept and returned.
+func deleteEmptyFolders(ctx context.Context, client *api.Client, folderIDs []string) ([]string, error) {
+	kept := make([]string, 0)
+	for i := len(folderIDs) - 1; i >= 0; i-- {
+		folder, err := client.GetFolder(ctx, folderIDs[i], &api.GetFolderOptions{
+			ContainChildrenFolders:   true,
+			ContainChildrenResources: true,
+		})
+		if err != nil {
+			// already deleted
+			continue
+		}
+		if len(folder.ChildrenFolders) > 0 || len(folder.ChildrenResources) > 0 {
+			kept = append([]string{folder.ID}, kept...)
+			continue
+		}
+		if err := client.DeleteFolder(ctx, folder.ID); err != nil {
+			return kept, fmt.Errorf("failed to delete folder %s: %w", folder.ID, err)
+		}
+	}
+	return kept, nil
+}
+
+// stringMapValue converts values to a types.Map of strings.
+func stringMapValue(values map[string]string) types.Map {
+	elems := make(map[string]attr.Value, len(values))
+	for key, el := range values {
+		elems[key] = types.StringValue(el)
+	}
+	return types.MapValueMust(types.StringType, elems)
+}
+
+func getCreatedFolders(ctx context.Context, private privateStateReader) ([]string, diag.Diagnostics) {
+	created := make([]string, 0)
+	data, diags := private.GetKey(ctx, createdFoldersKey)
+	if diags.HasError() || len(data) == 0 {
+		return created, diags
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		diags.AddError("Unable to decode created folders", err.Error())
+	}
+	return created, diags
+}
+
+func setCreatedFolders(ctx context.Context, private privateState, created []string) diag.Diagnostics {
+	data, err := json.Marshal(created)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to encode created folders", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, createdFoldersKey, data)
+}
+
+// isPathConfigured reports whether path was set in the configuration of the
+// folder. States written before this was recorded count as configured.
+func isPathConfigured(ctx context.Context, private privateStateReader) (bool, diag.Diagnostics) {
+	data, diags := private.GetKey(ctx, pathConfiguredKey)
+	if diags.HasError() || len(data) == 0 {
+		return true, diags
+	}
+	return string(data) == "true", diags
+}
+
+func setPathConfigured(ctx context.Context, private privateState, configured bool) diag.Diagnostics {
+	return private.SetKey(ctx, pathConfiguredKey, []byte(strconv.FormatBool(configured)))
+}
+
+// folderNameFromPath plans the name attribute as the last folder name of the
+// configured path.
+type folderNameFromPath struct{}
+
+// Description returns a plain text description of the modifier's behavior.
+func (m folderNameFromPath) Description(_ context.Context) string {
+	return "Defaults to the last folder name of path."
+}
+
+// MarkdownDescription returns a markdown formatted description of the modifier's behavior.
+func (m folderNameFromPath) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// PlanModifyString implements the plan modification logic.
+func (m folderNameFromPath) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+
+	var folderPath types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("path"), &folderPath)...)
+	if folderPath.IsNull() || folderPath.IsUnknown() {
+		return
+	}
+	names, err := splitFolderPath(folderPath.ValueString())
+	if err != nil {
+		return
+	}
+	resp.PlanValue = types.StringValue(names[len(names)-1])
+}
+
+// folderParentDefault defaults the folder_parent_id attribute to the root
+// folder, unless the parent is determined by the configured path.
+type folderParentDefault struct{}
+
+// Description returns a plain text description of the modifier's behavior.
+func (m folderParentDefault) Description(_ context.Context) string {
+	return "Defaults to the root folder, or to the parent folder of path."
+}
+
+// MarkdownDescription returns a markdown formatted description of the modifier's behavior.
+func (m folderParentDefault) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// PlanModifyString implements the plan modification logic.
+func (m folderParentDefault) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+
+	var configPath, statePath types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("path"), &configPath)...)
+	if configPath.IsNull() {
+		resp.PlanValue = types.StringValue("")
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("path"), &statePath)...)
+		if configPath.Equal(statePath) {
+			resp.PlanValue = req.StateValue
+			return
+		}
+	}
+	resp.PlanValue = types.StringUnknown()
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFolderPath(t *testing.T) {
+	names, err := splitFolderPath("Platform/Team/Env")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Platform", "Team", "Env"}, names)
+
+	_, err = splitFolderPath("Platform//Env")
+	assert.Error(t, err)
+	_, err = splitFolderPath("/Platform")
+	assert.Error(t, err)
+}
+
+func TestFolderPathOf(t *testing.T) {
+	folders := []api.Folder{
+		{ID: "f3", Name: "Env", FolderParentID: "f2"},
+		{ID: "f1", Name: "Platform"},
+		{ID: "f2", Name: "Team", FolderParentID: "f1"},
+	}
+
+	folderPath, idsByPath := folderPathOf(folders, "f3")
+	assert.Equal(t, "Platform/Team/Env", folderPath)
+	assert.Equal(t, map[string]string{"Platform": "f1", "Platform/Team": "f2", "Platform/Team/Env": "f3"}, idsByPath)
+
+	child, err := findChildFolder(folders, "f1", "Team")
+	assert.NoError(t, err)
+	assert.Equal(t, "f2", child.ID)
+	child, err = findChildFolder(folders, "", "Team")
+	assert.NoError(t, err)
+	assert.Nil(t, child)
+}
+
+func TestResolveFolderRef(t *testing.T) {
+	folders := []api.Folder{
+		{ID: "f1", Name: "Platform"},
+		{ID: "f2", Name: "Archive", FolderParentID: "f1"},
+	}
+
+	assert.Equal(t, "f2", resolveFolderRef(folders, "f2"))
+	assert.Equal(t, "f2", resolveFolderRef(folders, "Platform/Archive"))
+	assert.Equal(t, "", resolveFolderRef(folders, "Archive"))
+}
+
+func TestFolderIDByPath(t *testing.T) {
+	folders := []api.Folder{
+		{ID: "f1", Name: "Platform"},
+		{ID: "f2", Name: "Team", FolderParentID: "f1"},
+		{ID: "f3", Name: "Team"},
+	}
+
+	id, err := folderIDByPath(folders, []string{"Platform", "Team"})
+	assert.NoError(t, err)
+	assert.Equal(t, "f2", id)
+
+	_, err = folderIDByPath(folders, []string{"Platform", "Env"})
+	assert.EqualError(t, err, `folder "Platform/Env" not found`)
+}
+
+func TestPathConfigured(t *testing.T) {
+	ctx := context.Background()
+	private := fakePrivateState{}
+
+	// States written before the flag was recorded look up the path as before.
+	configured, diags := isPathConfigured(ctx, private)
+	assert.False(t, diags.HasError())
+	assert.True(t, configured)
+
+	setPathConfigured(ctx, private, false)
+	configured, _ = isPathConfigured(ctx, private)
+	assert.False(t, configured)
+
+	setPathConfigured(ctx, private, true)
+	configured, _ = isPathConfigured(ctx, private)
+	assert.True(t, configured)
+}
@@ -0,0 +1,451 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &folderPermissionsResource{}
+	_ resource.ResourceWithConfigure   = &folderPermissionsResource{}
+	_ resource.ResourceWithImportState = &folderPermissionsResource{}
+)
+
+// NewFolderPermissionsResource is a helper function to simplify the provider implementation.
+func NewFolderPermissionsResource() resource.Resource {
+	return &folderPermissionsResource{}
+}
+
+// folderPermissionsResource is the resource implementation.
+type folderPermissionsResource struct {
+	client *PassboltClient
+}
+
+type folderPermissionsModel struct {
+	ID               types.String            `tfsdk:"id"`
+	FolderID         types.String            `tfsdk:"folder_id"`
+	Permissions      []folderPermissionModel `tfsdk:"permissions"`
+	Propagate        types.Bool              `tfsdk:"propagate"`
+	PropagationDrift types.List              `tfsdk:"propagation_drift"`
+}
+
+type folderPermissionModel struct {
+	ARO        types.String `tfsdk:"aro"`
+	AROID      types.String `tfsdk:"aro_id"`
+	Permission types.String `tfsdk:"permission"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *folderPermissionsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *folderPermissionsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_folder_permissions"
+}
+
+// Schema defines the schema for the resource.
+func (r *folderPermissionsResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the complete set of permissions of a Passbolt Folder. Permissions not listed here are revoked.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The folder ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"folder_id": schema.StringAttribute{
+				Description: "The ID of the folder whose permissions are managed.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"permissions": schema.SetNestedAttribute{
+				Description:  "The permissions of the folder.",
+				Required:     true,
+				NestedObject: folderPermissionObject(),
+			},
+			"propagate": schema.BoolAttribute{
+				Description: "Whether to grant the permissions on all sub-folders and resources of the folder as well. Permissions of children which are not listed here are left untouched.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"propagation_drift": schema.ListAttribute{
+				Description: "The sub-folders and resources which are missing any of the permissions. Only populated if `propagate` is set.",
+				ElementType: types.StringType,
+				Computed:    true,
+				Default:     listdefault.StaticValue(stringListValue(nil)),
+			},
+		},
+	}
+}
+
+// Create a new resource.
+func (r *folderPermissionsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan folderPermissionsModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := applyFolderPermissions(ctx, r.client, plan.FolderID.ValueString(), plan.Permissions); err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to set permissions of folder: %s", plan.FolderID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+	if plan.Propagate.ValueBool() {
+		if err := propagateGrants(ctx, r.client, plan.FolderID.ValueString(), folderPermissionGrants(plan.Permissions)); err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to propagate permissions of folder: %s", plan.FolderID.ValueString()),
+				err.Error(),
+			)
+			return
+		}
+	}
+	plan.PropagationDrift = stringListValue(nil)
+
+	plan.ID = plan.FolderID
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *folderPermissionsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state folderPermissionsModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	folder, err := r.client.Client.GetFolder(ctx, state.FolderID.ValueString(), &api.GetFolderOptions{ContainPermissions: true})
+	if err != nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(folder.ID)
+	state.Permissions = folderPermissionModels(folder.Permissions, state.Permissions)
+
+	drift := make([]string, 0)
+	if state.Propagate.ValueBool() {
+		drift, err = getPropagationDrift(ctx, r.client.Client, folder.ID, folderPermissionGrants(state.Permissions))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to check propagated permissions of folder: %s", folder.ID),
+				err.Error(),
+			)
+			return
+		}
+	}
+	state.PropagationDrift = stringListValue(drift)
+	if state.Propagate.IsNull() {
+		state.Propagate = types.BoolValue(false)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *folderPermissionsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan folderPermissionsModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := applyFolderPermissions(ctx, r.client, plan.FolderID.ValueString(), plan.Permissions); err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to update permissions of folder: %s", plan.FolderID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+	if plan.Propagate.ValueBool() {
+		if err := propagateGrants(ctx, r.client, plan.FolderID.ValueString(), folderPermissionGrants(plan.Permissions)); err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to propagate permissions of folder: %s", plan.FolderID.ValueString()),
+				err.Error(),
+			)
+			return
+		}
+	}
+	plan.PropagationDrift = stringListValue(nil)
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete revokes all managed permissions except the ones of the acting user,
+// so the folder stays accessible to the provider.
+func (r *folderPermissionsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state folderPermissionsModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	folder, err := r.client.Client.GetFolder(ctx, state.FolderID.ValueString(), &api.GetFolderOptions{ContainPermissions: true})
+	if err != nil {
+		// folder already deleted
+		return
+	}
+
+	remaining := make([]folderPermissionModel, 0)
+	for _, pem := range folder.Permissions {
+		if pem.ARO == "User" && pem.AROForeignKey == r.client.Client.GetUserID() {
+			remaining = append(remaining, folderPermissionModel{
+				ARO:        types.StringValue(pem.ARO),
+				AROID:      types.StringValue(pem.AROForeignKey),
+				Permission: types.StringValue(permissionLevelName(pem.Type)),
+			})
+		}
+	}
+
+	if err := applyFolderPermissions(ctx, r.client, state.FolderID.ValueString(), remaining); err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to revoke permissions of folder: %s", state.FolderID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
+	if state.Propagate.ValueBool() {
+		revokes := make([]helper.ShareOperation, 0)
+		for _, grant := range folderPermissionGrants(state.Permissions) {
+			if grant.ARO == "User" && grant.AROID == r.client.Client.GetUserID() {
+				continue
+			}
+			grant.Type = permissionDelete
+			revokes = append(revokes, grant)
+		}
+		if err := propagateGrants(ctx, r.client, state.FolderID.ValueString(), revokes); err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to revoke propagated permissions of folder: %s", state.FolderID.ValueString()),
+				err.Error(),
+			)
+			return
+		}
+	}
+}
+
+// ImportState imports the permissions of a folder by its ID.
+func (r *folderPermissionsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("folder_id"), req.ID)...)
+}
+
+// applyFolderPermissions makes the folder's permissions match desired in a single share call.
+func applyFolderPermissions(ctx context.Context, client *PassboltClient, folderID string, desired []folderPermissionModel) error {
+	folder, err := client.Client.GetFolder(ctx, folderID, &api.GetFolderOptions{ContainPermissions: true})
+	if err != nil {
+		return fmt.Errorf("failed to get folder: %w", err)
+	}
+
+	changes, err := folderPermissionChanges(folderID, folder.Permissions, desired)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := client.checkPermissionLockout(ctx, folder.Permissions, applyPermissionChanges(folder.Permissions, changes)); err != nil {
+		return err
+	}
+
+	if err := client.Client.ShareFolder(ctx, folderID, changes); err != nil {
+		return fmt.Errorf("failed to share folder: %w", err)
+	}
+	return nil
+}
+
+// folderPermissionObject is the schema of a single folder permission.
+func folderPermissionObject() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"aro": schema.StringAttribute{
+				Description: "The type of the permission holder, either: User, Group",
+				Required:    true,
+				Validators: []validator.String{
+					stringOneOf("User", "Group"),
+				},
+			},
+			"aro_id": schema.StringAttribute{
+				Description: "The ID of the user or group.",
+				Required:    true,
+			},
+			"permission": schema.StringAttribute{
+				Description: "The permission to grant, either: read, update, owner or the numeric aliases 1, 7, 15.",
+				Required:    true,
+				Validators: []validator.String{
+					stringOneOf(permissionLevelValues...),
+				},
+			},
+		},
+	}
+}
+
+// folderPermissionModels converts the permissions of a folder to their models,
+// keeping the spelling (name or number) of the permission levels in prior.
+func folderPermissionModels(permissions []api.Permission, prior []folderPermissionModel) []folderPermissionModel {
+	levels := make(map[string]string, len(prior))
+	for _, el := range prior {
+		levels[el.ARO.ValueString()+"/"+el.AROID.ValueString()] = el.Permission.ValueString()
+	}
+
+	models := make([]folderPermissionModel, 0, len(permissions))
+	for _, pem := range permissions {
+		like, ok := levels[pem.ARO+"/"+pem.AROForeignKey]
+		if !ok {
+			like = permissionLevelName(permissionRead)
+		}
+		models = append(models, folderPermissionModel{
+			ARO:        types.StringValue(pem.ARO),
+			AROID:      types.StringValue(pem.AROForeignKey),
+			Permission: types.StringValue(formatPermissionLevel(pem.Type, like)),
+		})
+	}
+	return models
+}
+
+// folderPermissionAttrTypes are the attribute types of a single folder permission.
+var folderPermissionAttrTypes = map[string]attr.Type{
+	"aro":        types.StringType,
+	"aro_id":     types.StringType,
+	"permission": types.StringType,
+}
+
+// folderPermissionsFromSet converts a known set of folder permissions to their models.
+func folderPermissionsFromSet(ctx context.Context, permissions types.Set) ([]folderPermissionModel, diag.Diagnostics) {
+	models := make([]folderPermissionModel, 0, len(permissions.Elements()))
+	diags := permissions.ElementsAs(ctx, &models, false)
+	return models, diags
+}
+
+// folderPermissionsSet converts folder permission models to a set.
+func folderPermissionsSet(ctx context.Context, models []folderPermissionModel) (types.Set, diag.Diagnostics) {
+	return types.SetValueFrom(ctx, types.ObjectType{AttrTypes: folderPermissionAttrTypes}, models)
+}
+
+// folderPermissionGrants converts the permissions to share operations, e.g. for propagation.
+func folderPermissionGrants(permissions []folderPermissionModel) []helper.ShareOperation {
+	grants := make([]helper.ShareOperation, 0, len(permissions))
+	for _, el := range permissions {
+		pemType, err := parsePermissionLevel(el.Permission.ValueString())
+		if err != nil {
+			continue
+		}
+		grants = append(grants, helper.ShareOperation{
+			Type:  pemType,
+			ARO:   el.ARO.ValueString(),
+			AROID: el.AROID.ValueString(),
+		})
+	}
+	return grants
+}
+
+// folderPermissionChanges computes the permission changes needed to turn the
+// current permissions of a folder into the desired ones.
+func folderPermissionChanges(folderID string, current []api.Permission, desired []folderPermissionModel) ([]api.Permission, error) {
+	changes := make([]api.Permission, 0)
+	wanted := make(map[string]bool, len(desired))
+
+	for _, el := range desired {
+		key := el.ARO.ValueString() + "/" + el.AROID.ValueString()
+		if wanted[key] {
+			return nil, fmt.Errorf("duplicate permission for %s %s", el.ARO.ValueString(), el.AROID.ValueString())
+		}
+		wanted[key] = true
+
+		pemType, err := parsePermissionLevel(el.Permission.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", el.ARO.ValueString(), el.AROID.ValueString(), err)
+		}
+
+		var existing *api.Permission
+		for i := range current {
+			if current[i].ARO == el.ARO.ValueString() && current[i].AROForeignKey == el.AROID.ValueString() {
+				existing = &current[i]
+				break
+			}
+		}
+
+		switch {
+		case existing == nil:
+			changes = append(changes, api.Permission{
+				IsNew:         true,
+				ACO:           "Folder",
+				ACOForeignKey: folderID,
+				ARO:           el.ARO.ValueString(),
+				AROForeignKey: el.AROID.ValueString(),
+				Type:          pemType,
+			})
+		case existing.Type != pemType:
+			changes = append(changes, api.Permission{
+				ID:            existing.ID,
+				ACO:           "Folder",
+				ACOForeignKey: folderID,
+				ARO:           existing.ARO,
+				AROForeignKey: existing.AROForeignKey,
+				Type:          pemType,
+			})
+		}
+	}
+
+	for _, pem := range current {
+		if !wanted[pem.ARO+"/"+pem.AROForeignKey] {
+			changes = append(changes, api.Permission{
+				ID:            pem.ID,
+				ACO:           "Folder",
+				ACOForeignKey: folderID,
+				ARO:           pem.ARO,
+				AROForeignKey: pem.AROForeignKey,
+				Type:          pem.Type,
+				Delete:        true,
+			})
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolderPermissionChanges(t *testing.T) {
+	current := []api.Permission{
+		{ID: "p1", ARO: "User", AROForeignKey: "u1", Type: 15},
+		{ID: "p2", ARO: "Group", AROForeignKey: "g1", Type: 1},
+		{ID: "p3", ARO: "User", AROForeignKey: "u2", Type: 7},
+	}
+	desired := []folderPermissionModel{
+		{ARO: types.StringValue("User"), AROID: types.StringValue("u1"), Permission: types.StringValue("15")},
+		{ARO: types.StringValue("Group"), AROID: types.StringValue("g1"), Permission: types.StringValue("7")},
+		{ARO: types.StringValue("Group"), AROID: types.StringValue("g2"), Permission: types.StringValue("1")},
+	}
+
+	changes, err := folderPermissionChanges("f1", current, desired)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []api.Permission{
+		{ID: "p2", ACO: "Folder", ACOForeignKey: "f1", ARO: "Group", AROForeignKey: "g1", Type: 7},
+		{IsNew: true, ACO: "Folder", ACOForeignKey: "f1", ARO: "Group", AROForeignKey: "g2", Type: 1},
+		{ID: "p3", ACO: "Folder", ACOForeignKey: "f1", ARO: "User", AROForeignKey: "u2", Type: 7, Delete: true},
+	}, changes)
+}
+
+func TestFolderPermissionChangesDuplicate(t *testing.T) {
+	desired := []folderPermissionModel{
+		{ARO: types.StringValue("User"), AROID: types.StringValue("u1"), Permission: types.StringValue("15")},
+		{ARO: types.StringValue("User"), AROID: types.StringValue("u1"), Permission: types.StringValue("1")},
+	}
+
+	_, err := folderPermissionChanges("f1", nil, desired)
+
+	assert.Error(t, err)
+}
+
+func TestFolderPermissionsSetRoundTrip(t *testing.T) {
+	models := []folderPermissionModel{
+		{ARO: types.StringValue("User"), AROID: types.StringValue("u1"), Permission: types.StringValue("owner")},
+		{ARO: types.StringValue("Group"), AROID: types.StringValue("g1"), Permission: types.StringValue("1")},
+	}
+
+	set, diags := folderPermissionsSet(context.Background(), models)
+	assert.False(t, diags.HasError())
+	decoded, diags := folderPermissionsFromSet(context.Background(), set)
+
+	assert.False(t, diags.HasError())
+	assert.ElementsMatch(t, models, decoded)
+}
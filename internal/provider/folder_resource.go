@@ -2,20 +2,33 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &folderResource{}
-	_ resource.ResourceWithConfigure = &folderResource{}
+	_ resource.Resource                   = &folderResource{}
+	_ resource.ResourceWithConfigure      = &folderResource{}
+	_ resource.ResourceWithValidateConfig = &folderResource{}
+	_ resource.ResourceWithModifyPlan     = &folderResource{}
 )
 
 // NewFolderResource is a helper function to simplify the provider implementation.
@@ -33,9 +46,33 @@ type foldersModelCreate struct {
 	ID             types.String `tfsdk:"id"`
 	Name           types.String `tfsdk:"name"`
 	Personal       types.Bool   `tfsdk:"personal"`
+	Permissions    types.Set    `tfsdk:"permissions"`
+	Created        types.String `tfsdk:"created"`
+	Modified       types.String `tfsdk:"modified"`
+	CreatedBy      types.String `tfsdk:"created_by"`
+	ModifiedBy     types.String `tfsdk:"modified_by"`
 	FolderParentId types.String `tfsdk:"folder_parent_id"`
+	Path           types.String `tfsdk:"path"`
+	IDsByPath      types.Map    `tfsdk:"ids_by_path"`
+	OnConflict     types.String `tfsdk:"on_conflict"`
+	ForceDestroy   types.Bool   `tfsdk:"force_destroy"`
+	OnDelete       types.String `tfsdk:"on_delete"`
 }
 
+// Values of the on_conflict attribute.
+const (
+	folderOnConflictError = "error"
+	folderOnConflictAdopt = "adopt"
+)
+
+// Values of the on_delete attribute. folderOnDeleteMoveTo is followed by the
+// ID or path of the folder to move the contents to.
+const (
+	folderOnDeleteFailIfNotEmpty = "fail_if_not_empty"
+	folderOnDeleteMoveToParent   = "move_to_parent"
+	folderOnDeleteMoveTo         = "move_to:"
+)
+
 // Configure adds the provider configured client to the resource.
 func (r *folderResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
@@ -70,25 +107,121 @@ func (r *folderResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Computed:    true,
 			},
 			"name": schema.StringAttribute{
-				Description: "The folder name",
-				Required:    true,
+				Description: "The folder name. Defaults to the last folder name of `path`.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					folderNameFromPath{},
+				},
 			},
 			"folder_parent_id": schema.StringAttribute{
-				Description: "The ID of the parent folder",
+				Description: "The ID of the parent folder. Conflicts with `path`.",
 				Optional:    true,
 				Computed:    true,
-				Default:     stringdefault.StaticString(""),
+				PlanModifiers: []planmodifier.String{
+					folderParentDefault{},
+				},
 			},
-			"personal": schema.BoolAttribute{
-				Description: "If the folder is a personal folder.",
+			"path": schema.StringAttribute{
+				Description: "The full path of the folder, e.g. `Platform/Team/Env`. Missing parent folders are created and deleted again with this folder if they are empty. Conflicts with `folder_parent_id`.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"ids_by_path": schema.MapAttribute{
+				Description: "The IDs of the folder and all its parent folders, keyed by their path.",
+				ElementType: types.StringType,
 				Computed:    true,
+			},
+			"on_conflict": schema.StringAttribute{
+				Description: "What to do if a folder of the same name already exists in the parent folder on create, either: error, adopt. Adopted folders are managed, and deleted, like folders created by terraform. Defaults to error.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(folderOnConflictError),
+				Validators: []validator.String{
+					stringOneOf(folderOnConflictError, folderOnConflictAdopt),
+				},
+			},
+			"force_destroy": schema.BoolAttribute{
+				Description: "Delete all folders and resources inside the folder, recursively, when the folder is destroyed. Takes precedence over `on_delete`.",
 				Optional:    true,
-				Default:     booldefault.StaticBool(true),
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"on_delete": schema.StringAttribute{
+				Description: "What to do with the contents of the folder when it is destroyed, either: fail_if_not_empty, move_to_parent or move_to:<folder ID or path>. Defaults to move_to_parent, like Passbolt itself does.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(folderOnDeleteMoveToParent),
+				Validators: []validator.String{
+					stringOneOfOrPrefix([]string{folderOnDeleteMoveTo}, folderOnDeleteFailIfNotEmpty, folderOnDeleteMoveToParent),
+				},
+			},
+			"personal": schema.BoolAttribute{
+				Description: "If the folder is a personal folder, i.e. it is not shared with anyone else. Derived by Passbolt from the folder's permissions. This attribute is read-only: configurations which set it must drop the argument when upgrading.",
+				Computed:    true,
+			},
+			"permissions": schema.SetNestedAttribute{
+				Description:  "The complete set of permissions of the folder. Permissions not listed here are revoked. The provider user must keep owner access unless `allow_self_downgrade` is set. If unset, permissions are not managed by this resource.",
+				Optional:     true,
+				NestedObject: folderPermissionObject(),
+			},
+			"created": schema.StringAttribute{
+				Description: "The time the folder was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"created_by": schema.StringAttribute{
+				Description: "The ID of the user who created the folder.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"modified": schema.StringAttribute{
+				Description: "The time the folder was last modified.",
+				Computed:    true,
+			},
+			"modified_by": schema.StringAttribute{
+				Description: "The ID of the user who last modified the folder.",
+				Computed:    true,
 			},
 		},
 	}
 }
 
+// ValidateConfig ensures the folder is either placed by path or by folder_parent_id.
+func (r *folderResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data foldersModelCreate
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Path.IsNull() {
+		if data.Name.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("name"), "Missing folder name", "One of name or path must be set.")
+		}
+		return
+	}
+	if data.Path.IsUnknown() {
+		return
+	}
+	if !data.FolderParentId.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("folder_parent_id"), "Invalid folder parent", "folder_parent_id conflicts with path.")
+	}
+	names, err := splitFolderPath(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid folder path", err.Error())
+		return
+	}
+	if !data.Name.IsNull() && !data.Name.IsUnknown() && data.Name.ValueString() != names[len(names)-1] {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Invalid folder name", "name must match the last folder name of path.")
+	}
+}
+
 // Create a new resource.
 func (r *folderResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -105,31 +238,91 @@ func (r *folderResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	created := make([]string, 0)
+	pathConfigured := !plan.Path.IsNull() && !plan.Path.IsUnknown()
+	resp.Diagnostics.Append(setPathConfigured(ctx, resp.Private, pathConfigured)...)
+	if pathConfigured {
+		names, err := splitFolderPath(plan.Path.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid folder path", err.Error())
+			return
+		}
+		parentID, createdParents, err := ensureFolderPath(ctx, r.client.Client, names[:len(names)-1])
+		created = append(created, createdParents...)
+		resp.Diagnostics.Append(setCreatedFolders(ctx, resp.Private, created)...)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to create parent folders of path: %s", plan.Path.ValueString()), err.Error())
+			r.deleteCreatedParents(ctx, created, &resp.Diagnostics)
+			return
+		}
+		plan.FolderParentId = types.StringValue(parentID)
+	}
+
 	// Generate API request body from plan
 	var folder = api.Folder{
 		Name:           plan.Name.ValueString(),
-		Personal:       plan.Personal.ValueBool(),
 		FolderParentID: plan.FolderParentId.ValueString(),
 	}
 
-	for _, el := range folders {
-		if el.Name == folder.Name && el.Personal == folder.Personal {
-			// folder already created
+	existing, err := findChildFolder(folders, folder.FolderParentID, folder.Name)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to create folder of name: %s", folder.Name), err.Error())
+		r.deleteCreatedParents(ctx, created, &resp.Diagnostics)
+		return
+	}
+	if existing != nil {
+		existingPath, _ := folderPathOf(folders, existing.ID)
+		if plan.OnConflict.ValueString() != folderOnConflictAdopt {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("folder of name: %s already exists", folder.Name),
+				fmt.Sprintf("The folder %s (ID: %s) already exists. Set on_conflict = \"adopt\" to manage it with terraform.", existingPath, existing.ID),
+			)
+			r.deleteCreatedParents(ctx, created, &resp.Diagnostics)
 			return
 		}
+		tflog.Info(ctx, "Adopting existing folder", map[string]interface{}{"folderId": existing.ID, "path": existingPath})
 	}
 
-	cFolder, errCreate := r.client.Client.CreateFolder(r.client.Context, folder)
-	if errCreate != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("failed to create folder of name: %s", folder.Name),
-			errCreate.Error(),
-		)
-		return
+	cFolder := existing
+	if cFolder == nil {
+		var errCreate error
+		cFolder, errCreate = r.client.Client.CreateFolder(ctx, folder)
+		if errCreate != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to create folder of name: %s", folder.Name),
+				errCreate.Error(),
+			)
+			r.deleteCreatedParents(ctx, created, &resp.Diagnostics)
+			return
+		}
 	}
+	// The folder exists now, so it is kept in state (tainted) if any of the
+	// following steps fail. Deleting it then deletes the created parents too.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), cFolder.ID)...)
 
 	// Map response body to schema and populate Computed attribute values
 	plan.ID = types.StringValue(cFolder.ID)
+	if !plan.Permissions.IsNull() {
+		permissions, diags := folderPermissionsFromSet(ctx, plan.Permissions)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if err := applyFolderPermissions(ctx, r.client, cFolder.ID, permissions); err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to set permissions of folder: %s", folder.Name), err.Error())
+		}
+	}
+	if err := r.refreshFolder(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Cannot get folder: %s", folder.Name), err.Error())
+		return
+	}
+	folderPath, idsByPath, err := r.getFolderPath(ctx, cFolder.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Cannot get path of folder: %s", folder.Name), err.Error())
+		return
+	}
+	plan.Path = types.StringValue(folderPath)
+	plan.IDsByPath = stringMapValue(idsByPath)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -149,19 +342,41 @@ func (r *folderResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	folder, err := r.client.Client.GetFolder(r.client.Context, state.ID.ValueString(), nil)
+	folder, err := getFolderIfExists(ctx, r.client.Client, state.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
-			fmt.Sprintf("Cannot get folder: %s", folder.Name),
+			fmt.Sprintf("Cannot get folder: %s", state.ID.ValueString()),
 			err.Error(),
 		)
 		return
 	}
+	if folder == nil {
+		tflog.Warn(ctx, "Folder no longer exists, removing from state", map[string]interface{}{"folderId": state.ID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Without a configured path the full path is only looked up again if the
+	// folder itself was renamed or moved, to save listing all folders.
+	pathConfigured, diags := isPathConfigured(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if pathConfigured || state.Path.IsNull() || state.Name.ValueString() != folder.Name || state.FolderParentId.ValueString() != folder.FolderParentID {
+		folderPath, idsByPath, err := r.getFolderPath(ctx, folder.ID)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Cannot get path of folder: %s", folder.Name), err.Error())
+			return
+		}
+		state.Path = types.StringValue(folderPath)
+		state.IDsByPath = stringMapValue(idsByPath)
+	}
 
 	state.ID = types.StringValue(folder.ID)
 	state.Name = types.StringValue(folder.Name)
-	state.Personal = types.BoolValue(folder.Personal)
 	state.FolderParentId = types.StringValue(folder.FolderParentID)
+	resp.Diagnostics.Append(setFolderMetadata(ctx, &state, folder)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -181,16 +396,42 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	var state foldersModelCreate
+	req.State.Get(ctx, &state)
+
+	created, diags := getCreatedFolders(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pathConfigured := !plan.Path.IsNull() && !plan.Path.IsUnknown()
+	resp.Diagnostics.Append(setPathConfigured(ctx, resp.Private, pathConfigured)...)
+
+	// A changed path may move and rename the folder.
+	if pathConfigured && !plan.Path.Equal(state.Path) {
+		names, err := splitFolderPath(plan.Path.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid folder path", err.Error())
+			return
+		}
+		parentID, createdParents, err := ensureFolderPath(ctx, r.client.Client, names[:len(names)-1])
+		created = append(created, createdParents...)
+		resp.Diagnostics.Append(setCreatedFolders(ctx, resp.Private, created)...)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to create parent folders of path: %s", plan.Path.ValueString()), err.Error())
+			return
+		}
+		plan.FolderParentId = types.StringValue(parentID)
+	}
+
 	// Generate API request body from plan
 	var folder = api.Folder{
 		Name:           plan.Name.ValueString(),
-		Personal:       plan.Personal.ValueBool(),
 		FolderParentID: plan.FolderParentId.ValueString(),
 	}
 
-	var state foldersModelCreate
-	req.State.Get(ctx, &state)
-	cFolder, err := r.client.Client.UpdateFolder(r.client.Context, state.ID.ValueString(), folder)
+	cFolder, err := r.client.Client.UpdateFolder(ctx, state.ID.ValueString(), folder)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to update folder of name: %s", folder.Name),
@@ -202,7 +443,7 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	// In order to re-parent a folder we need to perform a move operation.
 	// This unfortunately can't be done via the UpdateFolder call.
 	if folder.FolderParentID != cFolder.FolderParentID {
-		err := r.client.Client.MoveFolder(r.client.Context, cFolder.ID, folder.FolderParentID)
+		err := r.client.Client.MoveFolder(ctx, cFolder.ID, folder.FolderParentID)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				fmt.Sprintf("failed to move folder of name: %s", folder.Name),
@@ -212,12 +453,52 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 		}
 	}
 
-	// Map response body to schema and populate Computed attribute values
 	plan.ID = types.StringValue(cFolder.ID)
+	if !plan.Permissions.IsNull() {
+		permissions, diags := folderPermissionsFromSet(ctx, plan.Permissions)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if err := applyFolderPermissions(ctx, r.client, cFolder.ID, permissions); err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to update permissions of folder: %s", folder.Name), err.Error())
+			return
+		}
+	}
+	if err := r.refreshFolder(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Cannot get folder: %s", folder.Name), err.Error())
+		return
+	}
+
+	// Clean up parent folders created for a previous path which are now unused.
+	folderPath, idsByPath, err := r.getFolderPath(ctx, cFolder.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Cannot get path of folder: %s", folder.Name), err.Error())
+		return
+	}
+	if len(created) > 0 {
+		unused := make([]string, 0)
+		inUse := make([]string, 0)
+		for _, id := range created {
+			if containsValue(idsByPath, id) {
+				inUse = append(inUse, id)
+			} else {
+				unused = append(unused, id)
+			}
+		}
+		kept, err := deleteEmptyFolders(ctx, r.client.Client, unused)
+		if err != nil {
+			resp.Diagnostics.AddWarning("Unable to delete unused parent folders", err.Error())
+		}
+		resp.Diagnostics.Append(setCreatedFolders(ctx, resp.Private, append(kept, inUse...))...)
+	}
+
+	// Map response body to schema and populate Computed attribute values
 	plan.Name = types.StringValue(cFolder.Name)
-	plan.Personal = types.BoolValue(cFolder.Personal)
 	// This uses folder instead of cFolder since cFolder may contain the previous parent ID if a move operation was performed.
 	plan.FolderParentId = types.StringValue(folder.FolderParentID)
+	plan.Path = types.StringValue(folderPath)
+	plan.IDsByPath = stringMapValue(idsByPath)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &plan)
@@ -237,7 +518,15 @@ func (r *folderResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	err := r.client.Client.DeleteFolder(r.client.Context, state.ID.ValueString())
+	if err := r.handleFolderContents(ctx, state); err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to delete folder with ID: %s", state.ID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
+	err := r.client.Client.DeleteFolder(ctx, state.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to delete folder with ID: %s", state.ID.ValueString()),
@@ -246,8 +535,232 @@ func (r *folderResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	// Only parent folders created by this resource are deleted, and only if
+	// nothing else was put into them in the meantime.
+	created, diags := getCreatedFolders(ctx, req.Private)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	if _, err := deleteEmptyFolders(ctx, r.client.Client, created); err != nil {
+		resp.Diagnostics.AddWarning("Unable to delete parent folders", err.Error())
+	}
+}
+
+// ModifyPlan warns about the folders and resources affected by destroying the folder.
+func (r *folderResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.Plan.Raw.IsNull() || req.State.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var state foldersModelCreate
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	children, err := getFolderChildren(ctx, r.client.Client, state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("Unable to list the contents of folder %s", state.Name.ValueString()),
+			fmt.Sprintf("The folders and resources affected by destroying the folder are unknown: %s", err.Error()),
+		)
+		return
+	}
+	if len(children) == 0 {
+		return
+	}
+
+	var action string
+	switch onDelete := state.OnDelete.ValueString(); {
+	case state.ForceDestroy.ValueBool():
+		action = "will be deleted"
+	case onDelete == folderOnDeleteFailIfNotEmpty:
+		action = "prevent the deletion, set force_destroy or change on_delete to delete the folder"
+	case strings.HasPrefix(onDelete, folderOnDeleteMoveTo):
+		action = "will be moved to " + strings.TrimPrefix(onDelete, folderOnDeleteMoveTo)
+	default:
+		action = "will be moved to the parent folder"
+	}
+	resp.Diagnostics.AddWarning(
+		fmt.Sprintf("Folder %s is not empty", state.Name.ValueString()),
+		fmt.Sprintf("The following contents %s:\n%s", action, folderContentsSummary(children)),
+	)
+}
+
+// handleFolderContents empties the folder according to force_destroy and on_delete,
+// or fails if the folder is not empty.
+func (r *folderResource) handleFolderContents(ctx context.Context, state foldersModelCreate) error {
+	folder, err := r.client.Client.GetFolder(ctx, state.ID.ValueString(), &api.GetFolderOptions{
+		ContainChildrenFolders:   true,
+		ContainChildrenResources: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get folder: %w", err)
+	}
+	if len(folder.ChildrenFolders) == 0 && len(folder.ChildrenResources) == 0 {
+		return nil
+	}
+
+	onDelete := state.OnDelete.ValueString()
+	if state.ForceDestroy.ValueBool() {
+		children, err := getFolderChildren(ctx, r.client.Client, folder.ID)
+		if err != nil {
+			return err
+		}
+		// Children are ordered top down, delete the deepest ones first.
+		for i := len(children) - 1; i >= 0; i-- {
+			child := children[i]
+			if child.Type == shareTypeResource {
+				err = r.client.Client.DeleteResource(ctx, child.ID)
+			} else {
+				err = r.client.Client.DeleteFolder(ctx, child.ID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to delete %s: %w", child, err)
+			}
+		}
+		return nil
+	}
+
+	// Contents are moved to the parent folder unless configured otherwise,
+	// also for states written before on_delete existed.
+	target := folder.FolderParentID
+	switch {
+	case onDelete == folderOnDeleteFailIfNotEmpty:
+		children, err := getFolderChildren(ctx, r.client.Client, folder.ID)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("folder is not empty, set force_destroy or change on_delete to delete it. Contents:\n%s", folderContentsSummary(children))
+	case strings.HasPrefix(onDelete, folderOnDeleteMoveTo):
+		folders, err := r.client.Client.GetFolders(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get folders: %w", err)
+		}
+		ref := strings.TrimPrefix(onDelete, folderOnDeleteMoveTo)
+		target = resolveFolderRef(folders, ref)
+		if target == "" {
+			return fmt.Errorf("failed to find folder %s to move the contents to", ref)
+		}
+	}
+
+	for _, el := range folder.ChildrenFolders {
+		if err := r.client.Client.MoveFolder(ctx, el.ID, target); err != nil {
+			return fmt.Errorf("failed to move folder %s (%s): %w", el.Name, el.ID, err)
+		}
+	}
+	for _, el := range folder.ChildrenResources {
+		if err := helper.MoveResource(ctx, r.client.Client, el.ID, target); err != nil {
+			return fmt.Errorf("failed to move resource %s (%s): %w", el.Name, el.ID, err)
+		}
+	}
+	return nil
+}
+
+// resolveFolderRef returns the ID of the folder referenced by ID or by path.
+func resolveFolderRef(folders []api.Folder, ref string) string {
+	for _, el := range folders {
+		if el.ID == ref {
+			return el.ID
+		}
+	}
+	for _, el := range folders {
+		if folderPath, _ := folderPathOf(folders, el.ID); folderPath == ref {
+			return el.ID
+		}
+	}
+	return ""
+}
+
+// folderContentsSummary lists the children of a folder, one per line.
+func folderContentsSummary(children []folderChild) string {
+	lines := make([]string, 0, len(children))
+	for _, el := range children {
+		lines = append(lines, "  - "+el.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// refreshFolder populates the computed attributes of model from the folder on the server.
+func (r *folderResource) refreshFolder(ctx context.Context, model *foldersModelCreate) error {
+	folder, err := r.client.Client.GetFolder(ctx, model.ID.ValueString(), &api.GetFolderOptions{ContainPermissions: true})
+	if err != nil {
+		return err
+	}
+	if diags := setFolderMetadata(ctx, model, folder); diags.HasError() {
+		return fmt.Errorf("%s: %s", diags[0].Summary(), diags[0].Detail())
+	}
+	return nil
+}
+
+// setFolderMetadata sets the computed attributes of model which are read as is
+// from the folder, and its permissions if they are managed.
+func setFolderMetadata(ctx context.Context, model *foldersModelCreate, folder *api.Folder) diag.Diagnostics {
+	model.Personal = types.BoolValue(folder.Personal)
+	model.Created = types.StringNull()
+	if folder.Created != nil {
+		model.Created = types.StringValue(folder.Created.Format(time.RFC3339))
+	}
+	model.Modified = types.StringNull()
+	if folder.Modified != nil {
+		model.Modified = types.StringValue(folder.Modified.Format(time.RFC3339))
+	}
+	model.CreatedBy = types.StringValue(folder.CreatedBy)
+	model.ModifiedBy = types.StringValue(folder.ModifiedBy)
+	if model.Permissions.IsNull() || model.Permissions.IsUnknown() {
+		return nil
+	}
+	prior, diags := folderPermissionsFromSet(ctx, model.Permissions)
+	if diags.HasError() {
+		return diags
+	}
+	model.Permissions, diags = folderPermissionsSet(ctx, folderPermissionModels(folder.Permissions, prior))
+	return diags
+}
+
+// getFolderIfExists returns the folder with its permissions, or nil if it does not exist.
+func getFolderIfExists(ctx context.Context, client *api.Client, folderID string) (*api.Folder, error) {
+	opts := &api.GetFolderOptions{ContainPermissions: true}
+	res, msg, err := client.DoCustomRequestAndReturnRawResponse(ctx, "GET", "/folders/"+folderID+".json", "v2", nil, opts)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var folder api.Folder
+	if err := json.Unmarshal(msg.Body, &folder); err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// deleteCreatedParents deletes the parent folders created for a folder which
+// could not be created, so they are not left behind untracked.
+func (r *folderResource) deleteCreatedParents(ctx context.Context, created []string, diags *diag.Diagnostics) {
+	if _, err := deleteEmptyFolders(ctx, r.client.Client, created); err != nil {
+		diags.AddWarning("Unable to delete parent folders", err.Error())
+	}
+}
+
+// getFolderPath returns the path of the folder and the IDs of all folders along it.
+func (r *folderResource) getFolderPath(ctx context.Context, folderID string) (string, map[string]string, error) {
+	folders, err := r.client.Client.GetFolders(ctx, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	folderPath, idsByPath := folderPathOf(folders, folderID)
+	return folderPath, idsByPath, nil
+}
+
+// containsValue reports whether value is one of the values of m.
+func containsValue(m map[string]string, value string) bool {
+	for _, el := range m {
+		if el == value {
+			return true
+		}
+	}
+	return false
 }
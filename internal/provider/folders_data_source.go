@@ -115,7 +115,7 @@ func (d *foldersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest
 func (d *foldersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state foldersDataSourceModel
 
-	folders, err := d.client.Client.GetFolders(d.client.Context, nil)
+	folders, err := d.client.Client.GetFolders(ctx, nil)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read folders", "",
@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &groupMembershipResource{}
+	_ resource.ResourceWithConfigure   = &groupMembershipResource{}
+	_ resource.ResourceWithImportState = &groupMembershipResource{}
+)
+
+// NewGroupMembershipResource is a helper function to simplify the provider implementation.
+func NewGroupMembershipResource() resource.Resource {
+	return &groupMembershipResource{}
+}
+
+// groupMembershipResource is the resource implementation.
+type groupMembershipResource struct {
+	client *PassboltClient
+}
+
+type groupMembershipModel struct {
+	ID        types.String `tfsdk:"id"`
+	GroupID   types.String `tfsdk:"group_id"`
+	UserID    types.String `tfsdk:"user_id"`
+	IsManager types.Bool   `tfsdk:"is_manager"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *groupMembershipResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *groupMembershipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group_membership"
+}
+
+// Schema defines the schema for the resource.
+func (r *groupMembershipResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a single member of a Passbolt Group. Other members of the group are left untouched. Creating a membership which already exists fails, import it instead. Do not combine with `group_users` of a `passbolt_group` resource for the same group.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the membership, composed of the group ID and the user ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"group_id": schema.StringAttribute{
+				Description: "The ID of the group.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				Description: "The ID of the user to add to the group.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"is_manager": schema.BoolAttribute{
+				Description: "Whether the user manages the group.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+// Create a new resource.
+func (r *groupMembershipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan groupMembershipModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.getMembership(ctx, plan.GroupID.ValueString(), plan.UserID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to get group: %s", plan.GroupID.ValueString()), err.Error())
+		return
+	}
+	if current != nil {
+		resp.Diagnostics.AddError(
+			"Group membership already exists",
+			fmt.Sprintf("The user %s is already a member of the group %s. Import it with the ID %s/%s to manage it.",
+				plan.UserID.ValueString(), plan.GroupID.ValueString(), plan.GroupID.ValueString(), plan.UserID.ValueString()),
+		)
+		return
+	}
+
+	err = r.applyMembership(ctx, plan, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to add user: %s to group: %s", plan.UserID.ValueString(), plan.GroupID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(plan.GroupID.ValueString() + "/" + plan.UserID.ValueString())
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *groupMembershipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state groupMembershipModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	membership, err := r.getMembership(ctx, state.GroupID.ValueString(), state.UserID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to get group: %s", state.GroupID.ValueString()), err.Error())
+		return
+	}
+	if membership == nil {
+		// The user or the whole group was removed outside of terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(state.GroupID.ValueString() + "/" + state.UserID.ValueString())
+	state.IsManager = types.BoolValue(membership.IsAdmin)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *groupMembershipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan groupMembershipModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.getMembership(ctx, plan.GroupID.ValueString(), plan.UserID.ValueString())
+	if err == nil {
+		err = r.applyMembership(ctx, plan, current)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to update user: %s in group: %s", plan.UserID.ValueString(), plan.GroupID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *groupMembershipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state groupMembershipModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	membership, err := r.getMembership(ctx, state.GroupID.ValueString(), state.UserID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to get group: %s", state.GroupID.ValueString()), err.Error())
+		return
+	}
+	if membership == nil {
+		// membership already removed
+		return
+	}
+
+	err = helper.UpdateGroup(ctx, r.client.Client, state.GroupID.ValueString(), "", []helper.GroupMembershipOperation{
+		{UserID: state.UserID.ValueString(), Delete: true},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to remove user: %s from group: %s", state.UserID.ValueString(), state.GroupID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+}
+
+// ImportState imports a membership by an ID of the form <group_id>/<user_id>.
+func (r *groupMembershipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	groupID, userID, found := strings.Cut(req.ID, "/")
+	if !found || groupID == "" || userID == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected an import ID of the form <group_id>/<user_id>, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("group_id"), groupID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), userID)...)
+}
+
+// applyMembership adds the user to the group or updates its manager flag,
+// given its current membership (or nil). Nothing is sent if the membership
+// already exists as planned, which helper.UpdateGroup would reject.
+func (r *groupMembershipResource) applyMembership(ctx context.Context, plan groupMembershipModel, current *api.GroupMembership) error {
+	changes := membershipChanges(current, plan.UserID.ValueString(), plan.IsManager.ValueBool())
+	if len(changes) == 0 {
+		return nil
+	}
+	// helper.UpdateGroup also encrypts the group's secrets for a new member.
+	return helper.UpdateGroup(ctx, r.client.Client, plan.GroupID.ValueString(), "", changes)
+}
+
+// membershipChanges returns the operations to make the user a member of the
+// group with the given manager flag, given its current membership (or nil).
+func membershipChanges(current *api.GroupMembership, userID string, isManager bool) []helper.GroupMembershipOperation {
+	if current != nil && current.IsAdmin == isManager {
+		return nil
+	}
+	return []helper.GroupMembershipOperation{{UserID: userID, IsGroupManager: isManager}}
+}
+
+// getMembership returns the membership of the user in the group, or nil if
+// the user is no member or the group does not exist.
+func (r *groupMembershipResource) getMembership(ctx context.Context, groupID string, userID string) (*api.GroupMembership, error) {
+	group, err := getGroupWithMembers(ctx, r.client.Client, groupID)
+	if err != nil || group == nil {
+		return nil, err
+	}
+	for i := range group.GroupUsers {
+		if group.GroupUsers[i].UserID == userID {
+			return &group.GroupUsers[i], nil
+		}
+	}
+	return nil, nil
+}
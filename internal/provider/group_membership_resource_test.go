@@ -0,0 +1,19 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembershipChanges(t *testing.T) {
+	// New members are added.
+	assert.Equal(t, []helper.GroupMembershipOperation{{UserID: "u1", IsGroupManager: true}}, membershipChanges(nil, "u1", true))
+
+	// Identical memberships are left alone, changed ones are updated.
+	current := &api.GroupMembership{ID: "m1", UserID: "u1", IsAdmin: false}
+	assert.Empty(t, membershipChanges(current, "u1", false))
+	assert.Equal(t, []helper.GroupMembershipOperation{{UserID: "u1", IsGroupManager: true}}, membershipChanges(current, "u1", true))
+}
@@ -2,19 +2,27 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &groupResource{}
-	_ resource.ResourceWithConfigure = &groupResource{}
+	_ resource.Resource                   = &groupResource{}
+	_ resource.ResourceWithConfigure      = &groupResource{}
+	_ resource.ResourceWithValidateConfig = &groupResource{}
+	_ resource.ResourceWithModifyPlan     = &groupResource{}
 )
 
 // NewGroupResource is a helper function to simplify the provider implementation.
@@ -29,15 +37,17 @@ type groupResource struct {
 
 // created, modified
 type groupModel struct {
-	ID         types.String      `tfsdk:"id"`
-	Name       types.String      `tfsdk:"name"`
-	GroupUsers []groupMembership `tfsdk:"group_users"`
+	ID         types.String `tfsdk:"id"`
+	Name       types.String `tfsdk:"name"`
+	GroupUsers types.Set    `tfsdk:"group_users"`
+
+	TransferOwnershipTo *ownershipTransferModel `tfsdk:"transfer_ownership_to"`
 }
 
 type groupMembership struct {
-	UserID  types.String `tfsdk:"user_id"`
-	IsAdmin types.Bool   `tfsdk:"is_admin"`
-	Delete  types.Bool   `tfsdk:"delete"`
+	UserID   types.String `tfsdk:"user_id"`
+	Username types.String `tfsdk:"username"`
+	IsAdmin  types.Bool   `tfsdk:"is_admin"`
 }
 
 // Configure adds the provider configured client to the resource.
@@ -77,22 +87,23 @@ func (r *groupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Description: "The group name.",
 				Required:    true,
 			},
-			"group_users": schema.ListNestedAttribute{
-				Required: true,
+			"group_users": schema.SetNestedAttribute{
+				Description: "The members of the group. Members not listed here are removed from the group. At least one member must be a group manager (`is_admin`).",
+				Required:    true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"user_id": schema.StringAttribute{
-							Description: "The id of the user to add.",
-							Required:    true,
+							Description: "The id of the user to add. Conflicts with `username`.",
+							Optional:    true,
+							Computed:    true,
 						},
-						"is_admin": schema.BoolAttribute{
-							Description: "Whether to make the user admin of the group.",
+						"username": schema.StringAttribute{
+							Description: "The username (email) of the user to add. Conflicts with `user_id`.",
 							Optional:    true,
 							Computed:    true,
-							Default:     booldefault.StaticBool(false),
 						},
-						"delete": schema.BoolAttribute{
-							Description: "Whether the user should be deleted from the group",
+						"is_admin": schema.BoolAttribute{
+							Description: "Whether to make the user admin of the group.",
 							Optional:    true,
 							Computed:    true,
 							Default:     booldefault.StaticBool(false),
@@ -100,10 +111,110 @@ func (r *groupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					},
 				},
 			},
+			"transfer_ownership_to": transferOwnershipSchema("group"),
 		},
 	}
 }
 
+// ValidateConfig ensures every member is referenced by exactly one of user_id or
+// username and that the group has at least one manager.
+func (r *groupResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data groupModel
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The members are validated once they are known.
+	if data.GroupUsers.IsUnknown() {
+		return
+	}
+	members, diags := groupMembersFromSet(ctx, data.GroupUsers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	hasManager := data.GroupUsers.IsNull()
+	for _, member := range members {
+		if member.IsAdmin.IsUnknown() || member.IsAdmin.ValueBool() {
+			hasManager = true
+		}
+		if member.UserID.IsNull() == member.Username.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("group_users"),
+				"Invalid group member",
+				"Exactly one of user_id or username must be set.",
+			)
+		}
+	}
+	if !hasManager {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("group_users"),
+			"Missing group manager",
+			"Passbolt requires every group to have at least one manager, set is_admin = true on at least one member.",
+		)
+	}
+}
+
+// ModifyPlan resolves the users of all members, so the plan fails for unknown
+// users and shows both the ID and the username of every member. On destroy it
+// warns about the items solely owned by the group.
+func (r *groupResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if r.client == nil {
+		return
+	}
+	if req.Plan.Raw.IsNull() {
+		if req.State.Raw.IsNull() {
+			return
+		}
+		var state groupModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(planOwnedItems(ctx, r.client.Client, "Group", state.ID.ValueString(), state.Name.ValueString(), state.TransferOwnershipTo, "")...)
+		return
+	}
+
+	var plan groupModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.GroupUsers.IsUnknown() {
+		return
+	}
+	members, diags := groupMembersFromSet(ctx, plan.GroupUsers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || !needsUserLookup(members) {
+		return
+	}
+	users, err := r.client.Client.GetUsers(ctx, &api.GetUsersOptions{})
+	if err != nil {
+		resp.Diagnostics.AddError("failed to get users", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resolveGroupMembers(users, members)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("group_users"), members)...)
+}
+
+// needsUserLookup reports whether any member is referenced by username or has
+// an unknown user_id, so the users have to be listed to plan the members.
+func needsUserLookup(members []groupMembership) bool {
+	for _, member := range members {
+		if (!member.Username.IsNull() && !member.Username.IsUnknown()) || member.UserID.IsUnknown() {
+			return true
+		}
+	}
+	return false
+}
+
 // Create a new resource.
 func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -114,15 +225,15 @@ func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	members := make([]api.GroupMembership, 0)
-	for _, member := range plan.GroupUsers {
-		elem := api.GroupMembership{
-			UserID:  member.UserID.ValueString(),
-			IsAdmin: member.IsAdmin.ValueBool(),
-			Delete:  member.Delete.ValueBool(),
-		}
+	desired, diags := r.resolveGroupMembers(ctx, plan.GroupUsers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-		members = append(members, elem)
+	members := make([]api.GroupMembership, 0, len(desired))
+	for _, op := range groupMembershipOperations(nil, desired) {
+		members = append(members, api.GroupMembership{UserID: op.UserID, IsAdmin: op.IsGroupManager})
 	}
 
 	// Generate API request body from plan
@@ -131,7 +242,7 @@ func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest,
 		GroupUsers: members,
 	}
 
-	cGroup, errCreate := r.client.Client.CreateGroup(r.client.Context, group)
+	cGroup, errCreate := r.client.Client.CreateGroup(ctx, group)
 	if errCreate != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to create group of name: %s", group.Name),
@@ -142,6 +253,8 @@ func (r *groupResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	// Map response body to schema and populate Computed attribute values
 	plan.ID = types.StringValue(cGroup.ID)
+	plan.GroupUsers, diags = groupMembersSet(ctx, desired)
+	resp.Diagnostics.Append(diags...)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -161,34 +274,22 @@ func (r *groupResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	opts := &api.GetGroupsOptions{
-		ContainGroupsUsers: true,
-	}
-	groups, err := r.client.Client.GetGroups(r.client.Context, opts)
+	group, err := getGroupWithMembers(ctx, r.client.Client, state.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
-			fmt.Sprintf("failed to get groups"),
+			fmt.Sprintf("failed to get group: %s", state.ID.ValueString()),
 			err.Error(),
 		)
 		return
 	}
-	for _, group := range groups {
-		if state.ID.ValueString() == group.ID {
-			state.Name = types.StringValue(group.Name)
-			members := make([]groupMembership, 0)
-			for _, member := range group.GroupUsers {
-				elem := groupMembership{
-					UserID:  types.StringValue(member.UserID),
-					IsAdmin: types.BoolValue(member.IsAdmin),
-					Delete:  types.BoolValue(member.Delete),
-				}
-
-				members = append(members, elem)
-			}
-			state.GroupUsers = members
-			break
-		}
+	if group == nil {
+		// The group was deleted outside of terraform.
+		resp.State.RemoveResource(ctx)
+		return
 	}
+	state.Name = types.StringValue(group.Name)
+	state.GroupUsers, diags = groupMembersSet(ctx, groupMembersFromAPI(group.GroupUsers))
+	resp.Diagnostics.Append(diags...)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -202,31 +303,38 @@ func (r *groupResource) Read(ctx context.Context, req resource.ReadRequest, resp
 func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	// Retrieve values from plan
 	var plan groupModel
-	diags := req.Config.Get(ctx, &plan)
+	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	members := make([]api.GroupMembership, 0)
-	for _, member := range plan.GroupUsers {
-		elem := api.GroupMembership{
-			UserID:  member.UserID.ValueString(),
-			IsAdmin: member.IsAdmin.ValueBool(),
-			Delete:  member.Delete.ValueBool(),
-		}
-
-		members = append(members, elem)
-	}
-
-	var update = api.GroupUpdate{
-		Name:         plan.Name.ValueString(),
-		GroupChanges: members,
+	desired, diags := r.resolveGroupMembers(ctx, plan.GroupUsers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	var state groupModel
 	req.State.Get(ctx, &state)
-	cGroup, err := r.client.Client.UpdateGroup(r.client.Context, state.ID.ValueString(), update)
+
+	// Diff against the members on the server, not against the state, to also
+	// revert changes made outside of terraform since the last refresh.
+	current, err := getGroupWithMembers(ctx, r.client.Client, state.ID.ValueString())
+	if err == nil && current == nil {
+		err = fmt.Errorf("group not found")
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to get group of name: %s", state.Name.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
+	// helper.UpdateGroup encrypts the group's secrets for new members.
+	changes := groupMembershipOperations(current.GroupUsers, desired)
+	err = helper.UpdateGroup(ctx, r.client.Client, state.ID.ValueString(), plan.Name.ValueString(), changes)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to update group of name: %s", state.Name.ValueString()),
@@ -235,19 +343,22 @@ func (r *groupResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	cGroup, err := getGroupWithMembers(ctx, r.client.Client, state.ID.ValueString())
+	if err == nil && cGroup == nil {
+		err = fmt.Errorf("group not found")
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to get group of name: %s", plan.Name.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
 	state.ID = types.StringValue(cGroup.ID)
 	state.Name = types.StringValue(cGroup.Name)
-	cMembers := make([]groupMembership, 0)
-	for _, member := range cGroup.GroupUsers {
-		elem := groupMembership{
-			UserID:  types.StringValue(member.UserID),
-			IsAdmin: types.BoolValue(member.IsAdmin),
-			Delete:  types.BoolValue(member.Delete),
-		}
-
-		cMembers = append(cMembers, elem)
-	}
-	state.GroupUsers = cMembers
+	state.GroupUsers, diags = groupMembersSet(ctx, groupMembersFromAPI(cGroup.GroupUsers))
+	resp.Diagnostics.Append(diags...)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -267,7 +378,16 @@ func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	err := r.client.Client.DeleteGroup(r.client.Context, state.ID.ValueString())
+	err := transferOwnedItems(ctx, r.client.Client, "Group", state.ID.ValueString(), state.TransferOwnershipTo)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to transfer ownership of group with ID: %s", state.ID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
+	err = r.client.Client.DeleteGroup(ctx, state.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to delete group with ID: %s", state.ID.ValueString()),
@@ -281,3 +401,158 @@ func (r *groupResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 }
+
+// resolveGroupMembers converts the known set of members to their models and
+// looks up their users to set both their user_id and username.
+func (r *groupResource) resolveGroupMembers(ctx context.Context, set types.Set) ([]groupMembership, diag.Diagnostics) {
+	members, diags := groupMembersFromSet(ctx, set)
+	if diags.HasError() {
+		return nil, diags
+	}
+	users, err := r.client.Client.GetUsers(ctx, &api.GetUsersOptions{})
+	if err != nil {
+		diags.AddError("failed to get users", err.Error())
+		return nil, diags
+	}
+	diags.Append(resolveGroupMembers(users, members)...)
+	return members, diags
+}
+
+// resolveGroupMembers sets user_id and username of every member whose user is
+// referenced by a known value. Members whose user does not exist or has not
+// completed the account setup are reported as errors.
+func resolveGroupMembers(users []api.User, members []groupMembership) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i := range members {
+		member := &members[i]
+		var user *api.User
+		var ref string
+		switch {
+		case !member.UserID.IsNull() && !member.UserID.IsUnknown():
+			ref = "user_id " + member.UserID.ValueString()
+			for j := range users {
+				if users[j].ID == member.UserID.ValueString() {
+					user = &users[j]
+					break
+				}
+			}
+		case !member.Username.IsNull() && !member.Username.IsUnknown():
+			ref = "username " + member.Username.ValueString()
+			for j := range users {
+				if users[j].Username == member.Username.ValueString() {
+					user = &users[j]
+					break
+				}
+			}
+		default:
+			continue
+		}
+
+		memberPath := path.Root("group_users")
+		if user == nil {
+			diags.AddAttributeError(memberPath, "Unknown group member", fmt.Sprintf("No user found with %s.", ref))
+			continue
+		}
+		if !user.Active {
+			diags.AddAttributeError(memberPath, "Inactive group member", fmt.Sprintf("The user with %s has not completed the account setup yet.", ref))
+			continue
+		}
+		member.UserID = types.StringValue(user.ID)
+		member.Username = types.StringValue(user.Username)
+	}
+	return diags
+}
+
+// getGroupOptions are the query parameters to fetch a single group with its members.
+type getGroupOptions struct {
+	ContainGroupsUsers     bool `url:"contain[groups_users],omitempty"`
+	ContainGroupsUsersUser bool `url:"contain[groups_users.user],omitempty"`
+}
+
+// getGroupWithMembers returns the group with its members, or nil if it does not exist.
+// api.Client.GetGroup does not support including the members.
+func getGroupWithMembers(ctx context.Context, client *api.Client, groupID string) (*api.Group, error) {
+	opts := &getGroupOptions{
+		ContainGroupsUsers:     true,
+		ContainGroupsUsersUser: true,
+	}
+	res, msg, err := client.DoCustomRequestAndReturnRawResponse(ctx, "GET", "/groups/"+groupID+".json", "v2", nil, opts)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var group api.Group
+	if err := json.Unmarshal(msg.Body, &group); err != nil {
+		return nil, err
+	}
+	if group.Deleted {
+		return nil, nil
+	}
+	return &group, nil
+}
+
+// groupMembersFromAPI converts the memberships of a group to their models.
+func groupMembersFromAPI(memberships []api.GroupMembership) []groupMembership {
+	members := make([]groupMembership, 0, len(memberships))
+	for _, member := range memberships {
+		members = append(members, groupMembership{
+			UserID:   types.StringValue(member.UserID),
+			Username: types.StringValue(member.User.Username),
+			IsAdmin:  types.BoolValue(member.IsAdmin),
+		})
+	}
+	return members
+}
+
+// groupMembershipAttrTypes are the attribute types of a single group member.
+var groupMembershipAttrTypes = map[string]attr.Type{
+	"user_id":  types.StringType,
+	"username": types.StringType,
+	"is_admin": types.BoolType,
+}
+
+// groupMembersFromSet converts a known set of group members to their models.
+func groupMembersFromSet(ctx context.Context, members types.Set) ([]groupMembership, diag.Diagnostics) {
+	models := make([]groupMembership, 0, len(members.Elements()))
+	diags := members.ElementsAs(ctx, &models, false)
+	return models, diags
+}
+
+// groupMembersSet converts group member models to a set.
+func groupMembersSet(ctx context.Context, members []groupMembership) (types.Set, diag.Diagnostics) {
+	return types.SetValueFrom(ctx, types.ObjectType{AttrTypes: groupMembershipAttrTypes}, members)
+}
+
+// groupMembershipOperations computes the membership operations which turn the
+// current members of a group into the desired ones: new members are added,
+// members whose admin flag differs are updated and all others are removed.
+// Unchanged members are left out, as helper.UpdateGroup rejects them.
+func groupMembershipOperations(current []api.GroupMembership, desired []groupMembership) []helper.GroupMembershipOperation {
+	changes := make([]helper.GroupMembershipOperation, 0)
+	wanted := make(map[string]bool, len(desired))
+	for _, member := range desired {
+		userID := member.UserID.ValueString()
+		wanted[userID] = true
+
+		var existing *api.GroupMembership
+		for i := range current {
+			if current[i].UserID == userID {
+				existing = &current[i]
+				break
+			}
+		}
+		if existing == nil || existing.IsAdmin != member.IsAdmin.ValueBool() {
+			changes = append(changes, helper.GroupMembershipOperation{UserID: userID, IsGroupManager: member.IsAdmin.ValueBool()})
+		}
+	}
+
+	for _, member := range current {
+		if !wanted[member.UserID] {
+			changes = append(changes, helper.GroupMembershipOperation{UserID: member.UserID, Delete: true})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveGroupMembers(t *testing.T) {
+	users := []api.User{
+		{ID: "u1", Username: "jane@example.com", Active: true},
+		{ID: "u2", Username: "john@example.com"},
+	}
+
+	members := []groupMembership{
+		{UserID: types.StringNull(), Username: types.StringValue("jane@example.com")},
+		{UserID: types.StringUnknown(), Username: types.StringUnknown()},
+	}
+	diags := resolveGroupMembers(users, members)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, "u1", members[0].UserID.ValueString())
+	assert.True(t, members[1].UserID.IsUnknown())
+
+	// Users who have not completed the setup and unknown users are rejected.
+	diags = resolveGroupMembers(users, []groupMembership{
+		{UserID: types.StringValue("u2"), Username: types.StringNull()},
+		{UserID: types.StringNull(), Username: types.StringValue("nobody@example.com")},
+	})
+	assert.Equal(t, 2, diags.ErrorsCount())
+}
+
+func TestNeedsUserLookup(t *testing.T) {
+	byID := groupMembership{UserID: types.StringValue("u1"), Username: types.StringUnknown()}
+	assert.False(t, needsUserLookup([]groupMembership{byID}))
+	assert.False(t, needsUserLookup(nil))
+
+	byName := groupMembership{UserID: types.StringUnknown(), Username: types.StringValue("jane@example.com")}
+	assert.True(t, needsUserLookup([]groupMembership{byID, byName}))
+	assert.True(t, needsUserLookup([]groupMembership{{UserID: types.StringUnknown(), Username: types.StringNull()}}))
+}
+
+func TestGroupMembersSet(t *testing.T) {
+	members := []groupMembership{
+		{UserID: types.StringValue("u1"), Username: types.StringValue("jane@example.com"), IsAdmin: types.BoolValue(true)},
+		{UserID: types.StringUnknown(), Username: types.StringValue("john@example.com"), IsAdmin: types.BoolValue(false)},
+	}
+
+	set, diags := groupMembersSet(context.Background(), members)
+	assert.False(t, diags.HasError())
+	decoded, diags := groupMembersFromSet(context.Background(), set)
+
+	assert.False(t, diags.HasError())
+	assert.ElementsMatch(t, members, decoded)
+}
+
+func TestGroupMembershipOperations(t *testing.T) {
+	current := []api.GroupMembership{
+		{ID: "m1", UserID: "u1", IsAdmin: true},
+		{ID: "m2", UserID: "u2"},
+		{ID: "m3", UserID: "u3"},
+	}
+	desired := []groupMembership{
+		{UserID: types.StringValue("u1"), IsAdmin: types.BoolValue(true)},
+		{UserID: types.StringValue("u2"), IsAdmin: types.BoolValue(true)},
+		{UserID: types.StringValue("u4"), IsAdmin: types.BoolValue(false)},
+	}
+
+	assert.Equal(t, []helper.GroupMembershipOperation{
+		{UserID: "u2", IsGroupManager: true},
+		{UserID: "u4"},
+		{UserID: "u3", Delete: true},
+	}, groupMembershipOperations(current, desired))
+}
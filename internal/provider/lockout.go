@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+)
+
+// checkPermissionLockout refuses a change of the permissions of a folder or
+// resource from before to after which would leave it without an owner, or
+// which would reduce the acting user's own access unless AllowSelfDowngrade is set.
+func (c *PassboltClient) checkPermissionLockout(ctx context.Context, before []api.Permission, after []api.Permission) error {
+	userID := c.Client.GetUserID()
+	groups, err := c.Client.GetGroups(ctx, &api.GetGroupsOptions{FilterHasUsers: []string{userID}})
+	if err != nil {
+		return fmt.Errorf("failed to get groups of the acting user: %w", err)
+	}
+	groupIDs := make([]string, 0, len(groups))
+	for _, el := range groups {
+		groupIDs = append(groupIDs, el.ID)
+	}
+	return checkLockout(before, after, userID, groupIDs, c.AllowSelfDowngrade)
+}
+
+// checkShareLockout is checkPermissionLockout for share operations.
+func (c *PassboltClient) checkShareLockout(ctx context.Context, before []api.Permission, changes []helper.ShareOperation) error {
+	return c.checkPermissionLockout(ctx, before, applyShareOperations(before, changes))
+}
+
+// checkLockout implements checkPermissionLockout for the acting user and its groups.
+func checkLockout(before []api.Permission, after []api.Permission, userID string, groupIDs []string, allowSelfDowngrade bool) error {
+	hasOwner := false
+	for _, el := range after {
+		if el.Type == permissionOwner {
+			hasOwner = true
+			break
+		}
+	}
+	if !hasOwner {
+		return fmt.Errorf("refusing to change permissions: at least one owner must remain")
+	}
+
+	if allowSelfDowngrade {
+		return nil
+	}
+	current := accessLevel(before, userID, groupIDs)
+	remaining := accessLevel(after, userID, groupIDs)
+	if remaining < current {
+		return fmt.Errorf(
+			"refusing to change permissions: the acting user would lose its %s access (remaining: %s), set allow_self_downgrade in the provider configuration to allow this",
+			permissionLevelName(current), accessLevelName(remaining),
+		)
+	}
+	return nil
+}
+
+// accessLevel returns the highest permission type granted to the user, directly or through one of its groups.
+func accessLevel(permissions []api.Permission, userID string, groupIDs []string) int {
+	level := 0
+	for _, el := range permissions {
+		granted := el.ARO == "User" && el.AROForeignKey == userID
+		if el.ARO == "Group" {
+			for _, groupID := range groupIDs {
+				if el.AROForeignKey == groupID {
+					granted = true
+					break
+				}
+			}
+		}
+		if granted && el.Type > level {
+			level = el.Type
+		}
+	}
+	return level
+}
+
+// accessLevelName is permissionLevelName which also names the absence of any access.
+func accessLevelName(level int) string {
+	if level == 0 {
+		return "none"
+	}
+	return permissionLevelName(level)
+}
+
+// applyShareOperations returns the permissions resulting from applying changes.
+func applyShareOperations(permissions []api.Permission, changes []helper.ShareOperation) []api.Permission {
+	result := append([]api.Permission{}, permissions...)
+	for _, change := range changes {
+		index := -1
+		for i := range result {
+			if result[i].ARO == change.ARO && result[i].AROForeignKey == change.AROID {
+				index = i
+				break
+			}
+		}
+
+		switch {
+		case change.Type == permissionDelete:
+			if index >= 0 {
+				result = append(result[:index], result[index+1:]...)
+			}
+		case index >= 0:
+			result[index].Type = change.Type
+		default:
+			result = append(result, api.Permission{ARO: change.ARO, AROForeignKey: change.AROID, Type: change.Type})
+		}
+	}
+	return result
+}
+
+// applyPermissionChanges returns the permissions resulting from sending changes to the share endpoint.
+func applyPermissionChanges(permissions []api.Permission, changes []api.Permission) []api.Permission {
+	operations := make([]helper.ShareOperation, 0, len(changes))
+	for _, el := range changes {
+		op := helper.ShareOperation{Type: el.Type, ARO: el.ARO, AROID: el.AROForeignKey}
+		if el.Delete {
+			op.Type = permissionDelete
+		}
+		operations = append(operations, op)
+	}
+	return applyShareOperations(permissions, operations)
+}
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLockout(t *testing.T) {
+	before := []api.Permission{
+		{ARO: "User", AROForeignKey: "me", Type: 15},
+		{ARO: "Group", AROForeignKey: "g1", Type: 15},
+	}
+
+	downgrade := applyShareOperations(before, []helper.ShareOperation{{ARO: "User", AROID: "me", Type: 7}})
+	assert.Error(t, checkLockout(before, downgrade, "me", nil, false))
+	assert.NoError(t, checkLockout(before, downgrade, "me", nil, true))
+	// Owner access through a group is kept.
+	assert.NoError(t, checkLockout(before, downgrade, "me", []string{"g1"}, false))
+
+	noOwner := applyShareOperations(before, []helper.ShareOperation{
+		{ARO: "User", AROID: "me", Type: -1},
+		{ARO: "Group", AROID: "g1", Type: 1},
+	})
+	assert.Error(t, checkLockout(before, noOwner, "me", nil, true))
+
+	grant := applyShareOperations(before, []helper.ShareOperation{{ARO: "User", AROID: "u2", Type: 1}})
+	assert.NoError(t, checkLockout(before, grant, "me", nil, false))
+	assert.Len(t, before, 2)
+}
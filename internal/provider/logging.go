@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/passbolt/go-passbolt/api"
+)
+
+// apiLogSubsystem is the tflog subsystem used for tracing go-passbolt API calls.
+// Its level can be set with TF_LOG_PROVIDER_PASSBOLT_API.
+const apiLogSubsystem = "passbolt_api"
+
+// apiLogLevelEnvs are the environment variables setting the level of the API
+// logging subsystem, most specific first.
+var apiLogLevelEnvs = []string{"TF_LOG_PROVIDER_PASSBOLT_API", "TF_LOG_PROVIDER", "TF_LOG"}
+
+// redactedValue replaces sensitive values in log output.
+const redactedValue = "***"
+
+// sensitiveLogKeys are field keys (in log fields and API payloads) whose values
+// must never be written to the logs. Matching is case-insensitive.
+var sensitiveLogKeys = []string{
+	"password",
+	"passphrase",
+	"private_key",
+	"secret",
+	"secrets",
+	"token",
+	"user_token_result",
+	"authenticationtoken",
+}
+
+// sensitiveLogPatterns match secret material wherever it shows up in log output.
+var sensitiveLogPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?s)-----BEGIN PGP (PRIVATE KEY BLOCK|MESSAGE)-----.*?-----END PGP (PRIVATE KEY BLOCK|MESSAGE)-----`),
+}
+
+// withRedactedLogging returns a context whose provider logger masks
+// passwords, passphrases, private keys and secret payloads.
+func withRedactedLogging(ctx context.Context) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, sensitiveLogFieldKeys()...)
+	ctx = tflog.MaskAllFieldValuesRegexes(ctx, sensitiveLogPatterns...)
+	ctx = tflog.MaskMessageRegexes(ctx, sensitiveLogPatterns...)
+	return ctx
+}
+
+// newAPILogContext sets up the redacted API logging subsystem. It is called
+// once when the provider is configured and the result is used by every API call.
+func newAPILogContext(ctx context.Context) context.Context {
+	ctx = withRedactedLogging(ctx)
+	ctx = tflog.NewSubsystem(ctx, apiLogSubsystem, tflog.WithLevelFromEnv(apiLogLevelEnvs[0]))
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, apiLogSubsystem, sensitiveLogFieldKeys()...)
+	ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, apiLogSubsystem, sensitiveLogPatterns...)
+	ctx = tflog.SubsystemMaskMessageRegexes(ctx, apiLogSubsystem, sensitiveLogPatterns...)
+	return ctx
+}
+
+// isAPITraceEnabled reports whether the API logging subsystem logs at TRACE
+// level, the only level request and response bodies are logged at.
+func isAPITraceEnabled(getenv func(string) string) bool {
+	for _, name := range apiLogLevelEnvs {
+		if level := strings.ToUpper(strings.TrimSpace(getenv(name))); level != "" {
+			// TF_LOG=JSON logs everything as JSON at TRACE level.
+			return level == "TRACE" || level == "JSON"
+		}
+	}
+	return false
+}
+
+// sensitiveLogFieldKeys returns sensitiveLogKeys in the spellings used for log fields.
+func sensitiveLogFieldKeys() []string {
+	keys := make([]string, 0, len(sensitiveLogKeys)*2)
+	for _, key := range sensitiveLogKeys {
+		keys = append(keys, key, strings.ToUpper(key[:1])+key[1:])
+	}
+	return keys
+}
+
+// loggingTransport traces every request the go-passbolt client sends with
+// redacted request and response bodies.
+type loggingTransport struct {
+	transport http.RoundTripper
+	// logCtx carries the API logging subsystem set up by newAPILogContext.
+	logCtx context.Context
+	// traceBodies enables buffering and redacting the bodies for the trace
+	// logs, which is too costly for large listings when nothing is logged.
+	traceBodies bool
+}
+
+// newLoggingHTTPClient returns an http.Client for the go-passbolt client which
+// traces all API calls to the API logging subsystem of logCtx.
+func newLoggingHTTPClient(logCtx context.Context) *http.Client {
+	return &http.Client{
+		Transport: &loggingTransport{
+			transport:   http.DefaultTransport,
+			logCtx:      logCtx,
+			traceBodies: isAPITraceEnabled(os.Getenv),
+		},
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := t.logCtx
+
+	fields := map[string]interface{}{
+		"http_method": req.Method,
+		"http_path":   req.URL.Path,
+	}
+	if t.traceBodies && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			fields["http_request_body"] = redactJSON(data)
+		}
+	}
+	tflog.SubsystemTrace(ctx, apiLogSubsystem, "Sending passbolt API request", fields)
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	fields["http_duration_ms"] = time.Since(start).Milliseconds()
+	delete(fields, "http_request_body")
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemError(ctx, apiLogSubsystem, "Passbolt API request failed", fields)
+		return resp, err
+	}
+
+	fields["http_status_code"] = resp.StatusCode
+	rec, record := req.Context().Value(responseRecorderKey{}).(*responseRecorder)
+	record = record && rec.matches(req)
+	if resp.Body != nil && (t.traceBodies || record) {
+		data, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if readErr == nil && t.traceBodies {
+			fields["http_response_body"] = redactJSON(data)
+		}
+		if record {
+			rec.body = data
+		}
+	}
+	tflog.SubsystemTrace(ctx, apiLogSubsystem, "Received passbolt API response", fields)
+	return resp, nil
+}
+
+type responseRecorderKey struct{}
+
+// responseRecorder keeps the response body of an API call made deep inside a
+// go-passbolt helper, which only returns parts of the response.
+type responseRecorder struct {
+	method string
+	path   string
+	body   []byte
+}
+
+// withResponseRecorder returns a context which records the response body of
+// the last request with the given method and path suffix.
+func withResponseRecorder(ctx context.Context, method string, path string) (context.Context, *responseRecorder) {
+	rec := &responseRecorder{method: method, path: path}
+	return context.WithValue(ctx, responseRecorderKey{}, rec), rec
+}
+
+func (r *responseRecorder) matches(req *http.Request) bool {
+	return req.Method == r.method && strings.HasSuffix(req.URL.Path, r.path)
+}
+
+// decode unmarshals the body of the recorded API response into v.
+func (r *responseRecorder) decode(v interface{}) error {
+	if r.body == nil {
+		return fmt.Errorf("no response recorded for %s %s", r.method, r.path)
+	}
+	var res api.APIResponse
+	if err := json.Unmarshal(r.body, &res); err != nil {
+		return err
+	}
+	return json.Unmarshal(res.Body, v)
+}
+
+// redactJSON returns the JSON document in data with the values of all
+// sensitive keys replaced. Non-JSON payloads are not logged at all.
+func redactJSON(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return redactedValue
+	}
+	redacted, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return redactedValue
+	}
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		secret := isSecretObject(v)
+		for key, el := range v {
+			if isSensitiveLogKey(key) || (secret && strings.EqualFold(key, "data")) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(el)
+			}
+		}
+	case []interface{}:
+		for i, el := range v {
+			v[i] = redactValue(el)
+		}
+	case string:
+		for _, pattern := range sensitiveLogPatterns {
+			v = pattern.ReplaceAllString(v, redactedValue)
+		}
+		return v
+	}
+	return value
+}
+
+func isSensitiveLogKey(key string) bool {
+	for _, el := range sensitiveLogKeys {
+		if strings.EqualFold(el, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretObject reports whether the JSON object is a secret, whose data holds
+// the encrypted password.
+func isSecretObject(v map[string]interface{}) bool {
+	_, hasData := v["data"]
+	_, hasResourceID := v["resource_id"]
+	return hasData && hasResourceID
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactJSON(t *testing.T) {
+	body := `{"header":{"status":"success"},"body":{"id":"1","name":"db","secrets":[{"data":"-----BEGIN PGP MESSAGE-----\nabc\n-----END PGP MESSAGE-----"}],"Password":"hunter2"}}`
+
+	redacted := redactJSON([]byte(body))
+
+	assert.Contains(t, redacted, `"name":"db"`)
+	assert.Contains(t, redacted, `"secrets":"***"`)
+	assert.Contains(t, redacted, `"Password":"***"`)
+	assert.NotContains(t, redacted, "hunter2")
+	assert.NotContains(t, redacted, "PGP MESSAGE")
+
+	// Only the data of secret objects is redacted.
+	secret := redactJSON([]byte(`{"body":{"id":"2","resource_id":"1","data":"c2VjcmV0"}}`))
+	assert.Contains(t, secret, `"data":"***"`)
+	assert.Contains(t, redactJSON([]byte(`{"body":{"data":"public"}}`)), `"data":"public"`)
+
+	// Payloads which are not JSON are never logged.
+	assert.Equal(t, "***", redactJSON([]byte("-----BEGIN PGP MESSAGE-----")))
+}
+
+func TestIsAPITraceEnabled(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+
+	assert.False(t, isAPITraceEnabled(env(nil)))
+	assert.True(t, isAPITraceEnabled(env(map[string]string{"TF_LOG": "trace"})))
+	assert.True(t, isAPITraceEnabled(env(map[string]string{"TF_LOG": "JSON"})))
+	// The most specific variable wins.
+	assert.False(t, isAPITraceEnabled(env(map[string]string{"TF_LOG": "TRACE", "TF_LOG_PROVIDER": "INFO"})))
+	assert.True(t, isAPITraceEnabled(env(map[string]string{"TF_LOG_PROVIDER": "INFO", "TF_LOG_PROVIDER_PASSBOLT_API": "TRACE"})))
+}
@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+)
+
+// ownedItemGroup is the type of an owned item for groups the deleted user is the sole manager of.
+const ownedItemGroup = "group"
+
+// ownershipTransferModel is the target of transfer_ownership_to.
+type ownershipTransferModel struct {
+	Type types.String `tfsdk:"type"`
+	ID   types.String `tfsdk:"id"`
+}
+
+// ownedItem is a resource, folder or group which blocks the deletion of a user or group.
+type ownedItem struct {
+	Type string
+	ID   string
+	Name string
+}
+
+// String identifies the item in plan warnings and error messages.
+func (i ownedItem) String() string {
+	return fmt.Sprintf("%s %s (%s)", i.Type, i.Name, i.ID)
+}
+
+// deleteDryRunErrors is the body of a failed delete dry-run, listing the
+// items which would be left without an owner or manager.
+type deleteDryRunErrors struct {
+	Errors struct {
+		Resources struct {
+			SoleOwner []deleteDryRunItem `json:"sole_owner"`
+		} `json:"resources"`
+		Folders struct {
+			SoleOwner []deleteDryRunItem `json:"sole_owner"`
+		} `json:"folders"`
+		Groups struct {
+			SoleManager []deleteDryRunItem `json:"sole_manager"`
+		} `json:"groups"`
+	} `json:"errors"`
+}
+
+type deleteDryRunItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// transferOwnershipSchema returns the transfer_ownership_to attribute for the resource named kind.
+func transferOwnershipSchema(kind string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: fmt.Sprintf("The user or group to transfer the resources and folders solely owned by this %s to before it is deleted. Without it the deletion fails while the %s is the sole owner of anything. Must be applied before the %s is destroyed to take effect.", kind, kind, kind),
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Description: "The type of the new owner, either: User, Group",
+				Required:    true,
+				Validators: []validator.String{
+					stringOneOf("User", "Group"),
+				},
+			},
+			"id": schema.StringAttribute{
+				Description: "The ID of the new owner.",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// getOwnedItems runs the delete dry-run of the user or group (aro "User" or "Group")
+// and returns the items which would block its deletion.
+func getOwnedItems(ctx context.Context, client *api.Client, aro string, id string) ([]ownedItem, error) {
+	path := fmt.Sprintf("/%ss/%s/dry-run.json", strings.ToLower(aro), id)
+	res, msg, err := client.DoCustomRequestAndReturnRawResponse(ctx, "DELETE", path, "v2", nil, nil)
+	if err == nil {
+		return []ownedItem{}, nil
+	}
+	if res == nil || res.StatusCode != http.StatusBadRequest || msg == nil {
+		return nil, err
+	}
+	items, parseErr := parseDeleteDryRun(msg.Body)
+	if parseErr != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// parseDeleteDryRun extracts the blocking items from the body of a failed delete dry-run.
+func parseDeleteDryRun(body []byte) ([]ownedItem, error) {
+	var dryRun deleteDryRunErrors
+	if err := json.Unmarshal(body, &dryRun); err != nil {
+		return nil, err
+	}
+
+	items := make([]ownedItem, 0)
+	for _, el := range dryRun.Errors.Folders.SoleOwner {
+		items = append(items, ownedItem{Type: shareTypeFolder, ID: el.ID, Name: el.Name})
+	}
+	for _, el := range dryRun.Errors.Resources.SoleOwner {
+		items = append(items, ownedItem{Type: shareTypeResource, ID: el.ID, Name: el.Name})
+	}
+	for _, el := range dryRun.Errors.Groups.SoleManager {
+		items = append(items, ownedItem{Type: ownedItemGroup, ID: el.ID, Name: el.Name})
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("delete dry-run failed without listing owned items")
+	}
+	return items, nil
+}
+
+// ownedItemsSummary lists the items, one per line.
+func ownedItemsSummary(items []ownedItem) string {
+	lines := make([]string, 0, len(items))
+	for _, el := range items {
+		lines = append(lines, "  - "+el.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// transferOwnership makes target an owner of the given folders and resources,
+// and a manager of the given groups. The acting user needs owner access to
+// the folders and resources to share them.
+func transferOwnership(ctx context.Context, client *api.Client, items []ownedItem, target ownershipTransferModel) error {
+	if err := checkTransferTarget(items, target); err != nil {
+		return err
+	}
+	aro := target.Type.ValueString()
+	aroID := target.ID.ValueString()
+	changes := []helper.ShareOperation{{Type: permissionOwner, ARO: aro, AROID: aroID}}
+
+	for _, el := range items {
+		var err error
+		switch el.Type {
+		case shareTypeFolder:
+			err = helper.ShareFolder(ctx, client, el.ID, changes)
+		case shareTypeResource:
+			err = helper.ShareResource(ctx, client, el.ID, changes)
+		case ownedItemGroup:
+			err = helper.UpdateGroup(ctx, client, el.ID, "", []helper.GroupMembershipOperation{
+				{UserID: aroID, IsGroupManager: true},
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to transfer ownership of %s to %s %s: %w", el, aro, aroID, err)
+		}
+	}
+	return nil
+}
+
+// checkTransferTarget returns an error if target cannot take over all items:
+// only users can manage groups.
+func checkTransferTarget(items []ownedItem, target ownershipTransferModel) error {
+	if target.Type.ValueString() == "User" {
+		return nil
+	}
+	for _, el := range items {
+		if el.Type == ownedItemGroup {
+			return fmt.Errorf("cannot transfer the management of %s to a group, only users can manage groups", el)
+		}
+	}
+	return nil
+}
+
+// planOwnedItems reports the items blocking the deletion of the user or group
+// (aro "User" or "Group") as a plan warning, prefixing the detail with reason.
+// A transfer target which cannot take over the items is an error.
+func planOwnedItems(ctx context.Context, client *api.Client, aro string, id string, name string, target *ownershipTransferModel, reason string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	items, err := getOwnedItems(ctx, client, aro, id)
+	if err != nil {
+		diags.AddWarning(
+			fmt.Sprintf("Unable to check what %s %s owns", aro, name),
+			fmt.Sprintf("The delete dry-run failed, so the deletion may fail if it is the sole owner of anything: %s", err.Error()),
+		)
+		return diags
+	}
+	if len(items) == 0 {
+		return diags
+	}
+	if target != nil {
+		if err := checkTransferTarget(items, *target); err != nil {
+			diags.AddAttributeError(path.Root("transfer_ownership_to"), "Invalid ownership transfer target", err.Error())
+			return diags
+		}
+	}
+	summary, detail := ownershipTransferWarning(aro, name, items, target)
+	diags.AddWarning(summary, reason+detail)
+	return diags
+}
+
+// ownershipTransferWarning describes what happens to the owned items when
+// the user or group is destroyed.
+func ownershipTransferWarning(kind string, name string, items []ownedItem, target *ownershipTransferModel) (string, string) {
+	summary := fmt.Sprintf("%s %s is the sole owner of resources, folders or groups", kind, name)
+	if target == nil {
+		return summary, fmt.Sprintf(
+			"The following items prevent the deletion, set transfer_ownership_to to transfer them:\n%s",
+			ownedItemsSummary(items),
+		)
+	}
+	return summary, fmt.Sprintf(
+		"The following items will be transferred to %s %s:\n%s",
+		target.Type.ValueString(), target.ID.ValueString(), ownedItemsSummary(items),
+	)
+}
+
+// transferOwnedItems transfers the items blocking the deletion of the user or
+// group (aro "User" or "Group") to target. Nothing happens without a target.
+func transferOwnedItems(ctx context.Context, client *api.Client, aro string, id string, target *ownershipTransferModel) error {
+	if target == nil {
+		return nil
+	}
+	items, err := getOwnedItems(ctx, client, aro, id)
+	if err != nil {
+		return fmt.Errorf("failed to find owned items: %w", err)
+	}
+	return transferOwnership(ctx, client, items, *target)
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeleteDryRun(t *testing.T) {
+	body := []byte(`{
+		"errors": {
+			"resources": {"sole_owner": [{"id": "r1", "name": "db"}]},
+			"folders": {"sole_owner": [{"id": "f1", "name": "ops"}]},
+			"groups": {"sole_manager": [{"id": "g1", "name": "devops"}]}
+		}
+	}`)
+
+	items, err := parseDeleteDryRun(body)
+	assert.NoError(t, err)
+	assert.Equal(t, []ownedItem{
+		{Type: shareTypeFolder, ID: "f1", Name: "ops"},
+		{Type: shareTypeResource, ID: "r1", Name: "db"},
+		{Type: ownedItemGroup, ID: "g1", Name: "devops"},
+	}, items)
+
+	// Errors which do not list owned items are not treated as ownership problems.
+	_, err = parseDeleteDryRun([]byte(`{"errors": {"id": {"uuid": "invalid"}}}`))
+	assert.Error(t, err)
+}
+
+func TestOwnershipTransferWarning(t *testing.T) {
+	items := []ownedItem{{Type: shareTypeResource, ID: "r1", Name: "db"}}
+
+	_, detail := ownershipTransferWarning("User", "jane@example.com", items, nil)
+	assert.Contains(t, detail, "set transfer_ownership_to")
+	assert.Contains(t, detail, "  - resource db (r1)")
+
+	_, detail = ownershipTransferWarning("User", "jane@example.com", items, &ownershipTransferModel{
+		Type: types.StringValue("Group"),
+		ID:   types.StringValue("g1"),
+	})
+	assert.Contains(t, detail, "will be transferred to Group g1")
+}
+
+func TestCheckTransferTarget(t *testing.T) {
+	items := []ownedItem{
+		{Type: shareTypeResource, ID: "r1", Name: "db"},
+		{Type: ownedItemGroup, ID: "g1", Name: "Ops"},
+	}
+	toUser := ownershipTransferModel{Type: types.StringValue("User"), ID: types.StringValue("u1")}
+	toGroup := ownershipTransferModel{Type: types.StringValue("Group"), ID: types.StringValue("g2")}
+
+	assert.NoError(t, checkTransferTarget(items, toUser))
+	assert.NoError(t, checkTransferTarget(items[:1], toGroup))
+	assert.ErrorContains(t, checkTransferTarget(items, toGroup), "group Ops (g1)")
+}
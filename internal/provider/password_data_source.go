@@ -3,17 +3,21 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
 	"github.com/passbolt/go-passbolt/helper"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ datasource.DataSource              = &passwordDataSource{}
-	_ datasource.DataSourceWithConfigure = &passwordDataSource{}
+	_ datasource.DataSource                   = &passwordDataSource{}
+	_ datasource.DataSourceWithConfigure      = &passwordDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &passwordDataSource{}
 )
 
 // NewPasswordDataSource is a helper function to simplify the provider implementation.
@@ -33,6 +37,7 @@ type passwordDataSourceModel struct {
 	Username       types.String `tfsdk:"username"`
 	Uri            types.String `tfsdk:"uri"`
 	FolderParentID types.String `tfsdk:"folder_parent_id"`
+	FolderPath     types.String `tfsdk:"folder_path"`
 	Password       types.String `tfsdk:"password"`
 }
 
@@ -62,26 +67,34 @@ func (d *passwordDataSource) Metadata(_ context.Context, req datasource.Metadata
 // Schema defines the schema for the data source.
 func (d *passwordDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Description: "Gets a Passbolt secret for the provided Resource ID.",
+		Description: "Gets a Passbolt secret by its Resource ID, or by its name, folder path, URI or username. Lookups other than by ID must match exactly one secret.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Description: "The Passbolt Resource ID of the secret (can be seen at the end of the URL of the secret in the web UI).",
-				Required:    true,
+				Description: "The Passbolt Resource ID of the secret (can be seen at the end of the URL of the secret in the web UI). Conflicts with the other lookup attributes, computed otherwise.",
+				Optional:    true,
+				Computed:    true,
 			},
 			"name": schema.StringAttribute{
-				Description: "The name of the secret.",
+				Description: "The name of the secret. Looks up the secret by name if `id` is not set.",
+				Optional:    true,
 				Computed:    true,
 			},
+			"folder_path": schema.StringAttribute{
+				Description: "The path of the folder containing the secret, such as Platform/Team. Limits the lookup to the secrets directly in this folder.",
+				Optional:    true,
+			},
 			"description": schema.StringAttribute{
 				Description: "The description of the secret. If not defined, it returns an empty string.",
 				Computed:    true,
 			},
 			"username": schema.StringAttribute{
-				Description: "The username of the secret.",
+				Description: "The username of the secret. Looks up the secret by username if `id` is not set.",
+				Optional:    true,
 				Computed:    true,
 			},
 			"uri": schema.StringAttribute{
-				Description: "The URI of the secret.",
+				Description: "The URI of the secret. Looks up the secret by URI if `id` is not set.",
+				Optional:    true,
 				Computed:    true,
 			},
 			"folder_parent_id": schema.StringAttribute{
@@ -97,13 +110,55 @@ func (d *passwordDataSource) Schema(_ context.Context, _ datasource.SchemaReques
 	}
 }
 
+// ValidateConfig ensures the secret is referenced either by ID or by the other lookup attributes.
+func (d *passwordDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data passwordDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lookup := !data.Name.IsNull() || !data.FolderPath.IsNull() || !data.Uri.IsNull() || !data.Username.IsNull()
+	switch {
+	case !data.ID.IsNull() && lookup:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("id"),
+			"Conflicting lookup attributes",
+			"Set either id, or any of name, folder_path, uri and username.",
+		)
+	case data.ID.IsNull() && !lookup:
+		resp.Diagnostics.AddError(
+			"Missing lookup attributes",
+			"Set either id, or any of name, folder_path, uri and username.",
+		)
+	case data.ID.IsNull() && data.Name.IsNull() && data.Uri.IsNull() && data.Username.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("folder_path"),
+			"Missing lookup attributes",
+			"folder_path only narrows a lookup, set name, uri or username as well.",
+		)
+	}
+}
+
 // Read refreshes the Terraform state with the latest data.
 func (d *passwordDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var data passwordDataSourceModel
 	diag := req.Config.Get(ctx, &data)
 	resp.Diagnostics.Append(diag...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.ID.IsNull() {
+		id, err := d.lookupResource(ctx, data)
+		if err != nil {
+			resp.Diagnostics.AddError("Unable to find resource", err.Error())
+			return
+		}
+		data.ID = types.StringValue(id)
+	}
 
-	folderParentID, name, username, uri, password, description, err := helper.GetResource(d.client.Context, d.client.Client, data.ID.ValueString())
+	folderParentID, name, username, uri, password, description, err := helper.GetResource(ctx, d.client.Client, data.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read resource "+data.ID.ValueString(), err.Error(),
@@ -125,3 +180,72 @@ func (d *passwordDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 }
+
+// lookupResource returns the ID of the only resource matching the lookup attributes of data.
+func (d *passwordDataSource) lookupResource(ctx context.Context, data passwordDataSourceModel) (string, error) {
+	// The server side search narrows down the resources, findResource then
+	// only keeps exact matches.
+	opts := &getResourcesOptions{FilterSearch: resourceSearchTerm(data.Name, data.Uri, data.Username)}
+	if !data.FolderPath.IsNull() {
+		names, err := splitFolderPath(data.FolderPath.ValueString())
+		if err != nil {
+			return "", err
+		}
+		folders, err := d.client.Client.GetFolders(ctx, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to get folders: %w", err)
+		}
+		folderID, err := folderIDByPath(folders, names)
+		if err != nil {
+			return "", err
+		}
+		opts.FilterHasParent = []string{folderID}
+	}
+
+	resources, err := searchResources(ctx, d.client.Client, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to get resources: %w", err)
+	}
+	resource, err := findResource(resources, data.Name, data.Uri, data.Username)
+	if err != nil {
+		return "", err
+	}
+	return resource.ID, nil
+}
+
+// resourceSearchTerm returns the most specific of the given values to search
+// resources by: the name, else the username, else the URI.
+func resourceSearchTerm(name types.String, uri types.String, username types.String) string {
+	for _, el := range []types.String{name, username, uri} {
+		if !el.IsNull() && !el.IsUnknown() {
+			return el.ValueString()
+		}
+	}
+	return ""
+}
+
+// findResource returns the only resource matching all given, non-null values.
+func findResource(resources []api.Resource, name types.String, uri types.String, username types.String) (*api.Resource, error) {
+	matches := make([]*api.Resource, 0)
+	for i := range resources {
+		el := &resources[i]
+		if (!name.IsNull() && el.Name != name.ValueString()) ||
+			(!uri.IsNull() && el.URI != uri.ValueString()) ||
+			(!username.IsNull() && el.Username != username.ValueString()) {
+			continue
+		}
+		matches = append(matches, el)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no resource matches the lookup")
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, 0, len(matches))
+	for _, el := range matches {
+		ids = append(ids, el.ID)
+	}
+	return nil, fmt.Errorf("%d resources match the lookup, add lookup attributes or use the id: %s", len(matches), strings.Join(ids, ", "))
+}
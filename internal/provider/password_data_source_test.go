@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindResource(t *testing.T) {
+	resources := []api.Resource{
+		{ID: "r1", Name: "db", Username: "admin", URI: "postgres://db1"},
+		{ID: "r2", Name: "db", Username: "readonly", URI: "postgres://db1"},
+		{ID: "r3", Name: "api", Username: "admin", URI: "https://api"},
+	}
+
+	found, err := findResource(resources, types.StringValue("db"), types.StringNull(), types.StringValue("readonly"))
+	assert.NoError(t, err)
+	assert.Equal(t, "r2", found.ID)
+
+	found, err = findResource(resources, types.StringNull(), types.StringValue("https://api"), types.StringNull())
+	assert.NoError(t, err)
+	assert.Equal(t, "r3", found.ID)
+
+	_, err = findResource(resources, types.StringValue("db"), types.StringNull(), types.StringNull())
+	assert.EqualError(t, err, "2 resources match the lookup, add lookup attributes or use the id: r1, r2")
+
+	_, err = findResource(resources, types.StringValue("cache"), types.StringNull(), types.StringNull())
+	assert.EqualError(t, err, "no resource matches the lookup")
+}
+
+func TestResourceSearchTerm(t *testing.T) {
+	name := types.StringValue("db")
+	uri := types.StringValue("https://db.example.com")
+	username := types.StringValue("admin")
+	null := types.StringNull()
+
+	assert.Equal(t, "db", resourceSearchTerm(name, uri, username))
+	assert.Equal(t, "admin", resourceSearchTerm(null, uri, username))
+	assert.Equal(t, "https://db.example.com", resourceSearchTerm(null, uri, null))
+	assert.Equal(t, "", resourceSearchTerm(null, null, null))
+}
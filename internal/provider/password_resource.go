@@ -2,13 +2,18 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/passbolt/go-passbolt/api"
 	"github.com/passbolt/go-passbolt/helper"
 )
 
@@ -38,6 +43,8 @@ type passwordModel struct {
 	FolderParent   types.String `tfsdk:"folder_parent"`
 	FolderParentId types.String `tfsdk:"folder_parent_id"`
 	Password       types.String `tfsdk:"password"`
+	Modified       types.String `tfsdk:"modified"`
+	ConflictPolicy types.String `tfsdk:"conflict_policy"`
 }
 
 // Configure adds the provider configured client to the resource.
@@ -110,6 +117,19 @@ func (r *passwordResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				Required:    true,
 				Sensitive:   true,
 			},
+			"modified": schema.StringAttribute{
+				Description: "The time the secret was last modified on the server.",
+				Computed:    true,
+			},
+			"conflict_policy": schema.StringAttribute{
+				Description: "What to do when the secret was modified on the server since the last refresh, either: `overwrite` (default) or `fail`. The check is skipped if no revision of the secret was recorded yet.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(conflictPolicyOverwrite),
+				Validators: []validator.String{
+					stringOneOf(conflictPolicyFail, conflictPolicyOverwrite),
+				},
+			},
 		},
 	}
 }
@@ -137,8 +157,10 @@ func (r *passwordResource) Create(ctx context.Context, req resource.CreateReques
 		}
 	}
 
+	// helper.CreateResource only returns the ID, the fingerprint is taken from its response.
+	createCtx, created := withResponseRecorder(ctx, "POST", "/resources.json")
 	resourceId, err := helper.CreateResource(
-		ctx,
+		createCtx,
 		r.client.Client,
 		plan.FolderParentId.ValueString(),
 		plan.Name.ValueString(),
@@ -181,6 +203,10 @@ func (r *passwordResource) Create(ctx context.Context, req resource.CreateReques
 
 	plan.ID = types.StringValue(resourceId)
 
+	fingerprint, fingerprintDiags := storePasswordFingerprint(ctx, created, resp.Private)
+	resp.Diagnostics.Append(fingerprintDiags...)
+	plan.Modified = types.StringValue(fingerprint.Modified)
+
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -192,19 +218,63 @@ func (r *passwordResource) Create(ctx context.Context, req resource.CreateReques
 // Read refreshes the Terraform state with the latest data.
 func (r *passwordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var state passwordModel
-	diag := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diag...)
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	folderParentID, name, username, uri, password, description, err := helper.GetResource(r.client.Context, r.client.Client, state.ID.ValueString())
+	ctx = withRedactedLogging(ctx)
+	res, err := getResourceWithSecret(ctx, r.client.Client, state.ID.ValueString())
 	if err != nil {
 		resp.State.RemoveResource(ctx)
 		return
 	}
+
+	fingerprint := newPasswordFingerprint(res)
+	storedFingerprint, diags := getPasswordFingerprint(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	folderParentID := res.FolderParentID
+	if !r.client.VerifySecretOnRefresh && storedFingerprint != nil && storedFingerprint.matches(fingerprint) {
+		// Neither the resource nor its secret changed since we last saw it, so the
+		// secret in state is still current and we can skip decrypting it.
+		tflog.Debug(ctx, "Skipping secret decryption for unchanged resource", map[string]interface{}{"resourceId": res.ID})
+		state.Name = types.StringValue(res.Name)
+		state.Username = types.StringValue(res.Username)
+		state.Uri = types.StringValue(res.URI)
+	} else {
+		rType, err := r.client.Client.GetResourceType(ctx, res.ResourceTypeID)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to get resource type for "+res.ID, err.Error(),
+			)
+			return
+		}
+
+		var name, username, uri, password, description string
+		folderParentID, name, username, uri, password, description, err = helper.GetResourceFromData(r.client.Client, *res, res.Secrets[0], *rType)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to decrypt resource "+res.ID, err.Error(),
+			)
+			return
+		}
+
+		if description != "" {
+			state.Description = types.StringValue(description)
+		}
+		state.Name = types.StringValue(name)
+		state.Username = types.StringValue(username)
+		state.Password = types.StringValue(password)
+		state.Uri = types.StringValue(uri)
+	}
+
 	if folderParentID != "" {
-		_, folderName, err := helper.GetFolder(r.client.Context, r.client.Client, folderParentID)
+		_, folderName, err := helper.GetFolder(ctx, r.client.Client, folderParentID)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Unable to get folder name for "+folderParentID, err.Error(),
@@ -214,15 +284,13 @@ func (r *passwordResource) Read(ctx context.Context, req resource.ReadRequest, r
 		state.FolderParent = types.StringValue(folderName)
 	}
 
-	if description != "" {
-		state.Description = types.StringValue(description)
+	state.Modified = types.StringValue(fingerprint.Modified)
+	if state.ConflictPolicy.IsNull() {
+		state.ConflictPolicy = types.StringValue(conflictPolicyOverwrite)
 	}
-	state.Name = types.StringValue(name)
-	state.Username = types.StringValue(username)
-	state.Password = types.StringValue(password)
-	state.Uri = types.StringValue(uri)
 
-	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(setPasswordFingerprint(ctx, resp.Private, fingerprint)...)
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -244,17 +312,24 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	ctx = withRedactedLogging(ctx)
 	ctx = tflog.SetField(ctx, "resourceId", state.ID.ValueString())
 	ctx = tflog.SetField(ctx, "Name", plan.Name.ValueString())
 	ctx = tflog.SetField(ctx, "Username", plan.Username.ValueString())
 	ctx = tflog.SetField(ctx, "Uri", plan.Uri.ValueString())
-	ctx = tflog.SetField(ctx, "Password", plan.Password.ValueString())
-	ctx = tflog.SetField(ctx, "Description", plan.Description.ValueString())
 	tflog.Debug(ctx, "passbolt.UpdateResource")
 
-	// Update Resource
+	if plan.ConflictPolicy.ValueString() == conflictPolicyFail {
+		resp.Diagnostics.Append(r.checkPasswordConflict(ctx, state.ID.ValueString(), req.Private)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Update Resource, helper.UpdateResource does not return the updated resource.
+	updateCtx, updated := withResponseRecorder(ctx, "PUT", "/resources/"+state.ID.ValueString()+".json")
 	err := helper.UpdateResource(
-		r.client.Context,
+		updateCtx,
 		r.client.Client,
 		state.ID.ValueString(),
 		plan.Name.ValueString(),
@@ -270,9 +345,8 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 	if plan.FolderParent.ValueString() != state.FolderParent.ValueString() {
-		folders, err := r.client.Client.GetFolders(r.client.Context, nil)
-		ctx = tflog.SetField(ctx, "folders", folders)
-		tflog.Debug(ctx, "passbolt.GetFolders")
+		folders, err := r.client.Client.GetFolders(ctx, nil)
+		tflog.Debug(ctx, "passbolt.GetFolders", map[string]interface{}{"folderCount": len(folders)})
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Unable to Read folders", err.Error(),
@@ -281,7 +355,7 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 		}
 		for _, folder := range folders {
 			if folder.Name == plan.FolderParent.ValueString() {
-				err = helper.MoveResource(r.client.Context, r.client.Client, state.ID.ValueString(), folder.ID)
+				err = helper.MoveResource(ctx, r.client.Client, state.ID.ValueString(), folder.ID)
 				ctx = tflog.SetField(ctx, "resourceId", state.ID.ValueString())
 				ctx = tflog.SetField(ctx, "oldFolderId", state.FolderParentId)
 				ctx = tflog.SetField(ctx, "newFolderId", folder.ID)
@@ -299,9 +373,8 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 	if plan.ShareGroup.ValueString() != state.ShareGroup.ValueString() {
 		permissedUsers := make([]string, 0)
 		permissedGroups := make([]string, 0)
-		groups, err := r.client.Client.GetGroups(r.client.Context, nil)
-		ctx = tflog.SetField(ctx, "groups", groups)
-		tflog.Debug(ctx, "passbolt.GetGroups")
+		groups, err := r.client.Client.GetGroups(ctx, nil)
+		tflog.Debug(ctx, "passbolt.GetGroups", map[string]interface{}{"groupCount": len(groups)})
 		if err != nil {
 			resp.Diagnostics.AddError("Unable to Groups", err.Error())
 			return
@@ -317,7 +390,20 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 			)
 			return
 		}
-		err = helper.ShareResourceWithUsersAndGroups(r.client.Context, r.client.Client, state.ID.ValueString(), permissedUsers, permissedGroups, 1)
+		permissions, err := r.client.Client.GetResourcePermissions(ctx, state.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Unable to get permissions of "+state.ID.ValueString(), err.Error())
+			return
+		}
+		shares := make([]helper.ShareOperation, 0, len(permissedGroups))
+		for _, groupID := range permissedGroups {
+			shares = append(shares, helper.ShareOperation{Type: 1, ARO: "Group", AROID: groupID})
+		}
+		if err := r.client.checkShareLockout(ctx, permissions, shares); err != nil {
+			resp.Diagnostics.AddError("Unable to share "+state.ID.ValueString()+" with "+plan.ShareGroup.ValueString(), err.Error())
+			return
+		}
+		err = helper.ShareResourceWithUsersAndGroups(ctx, r.client.Client, state.ID.ValueString(), permissedUsers, permissedGroups, 1)
 		ctx = tflog.SetField(ctx, "resourceId", state.ID.ValueString())
 		ctx = tflog.SetField(ctx, "permissedUsers", permissedUsers)
 		ctx = tflog.SetField(ctx, "permissedGroups", permissedGroups)
@@ -342,6 +428,11 @@ func (r *passwordResource) Update(ctx context.Context, req resource.UpdateReques
 	state.FolderParent = plan.FolderParent
 	state.ShareGroup = plan.ShareGroup
 
+	fingerprint, fingerprintDiags := storePasswordFingerprint(ctx, updated, resp.Private)
+	resp.Diagnostics.Append(fingerprintDiags...)
+	state.Modified = types.StringValue(fingerprint.Modified)
+	state.ConflictPolicy = plan.ConflictPolicy
+
 	setStateDiags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(setStateDiags...)
 	if resp.Diagnostics.HasError() {
@@ -367,3 +458,143 @@ func (r *passwordResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 }
+
+// storePasswordFingerprint records the server-side fingerprint of a resource
+// from the recorded response of the call which wrote it, so the next refresh
+// can skip decryption.
+func storePasswordFingerprint(ctx context.Context, written *responseRecorder, private privateState) (passwordFingerprint, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var res api.Resource
+	if err := written.decode(&res); err != nil {
+		diags.AddWarning(
+			"Unable to fingerprint resource",
+			"The secret will be decrypted again on the next refresh. "+err.Error(),
+		)
+		return passwordFingerprint{}, diags
+	}
+	fingerprint := newPasswordFingerprint(&res)
+	diags.Append(setPasswordFingerprint(ctx, private, fingerprint)...)
+	return fingerprint, diags
+}
+
+// checkPasswordConflict compares the fingerprint recorded while planning with
+// the server and reports a conflict if somebody changed the secret in between.
+func (r *passwordResource) checkPasswordConflict(ctx context.Context, resourceID string, private privateStateReader) diag.Diagnostics {
+	planned, diags := getPasswordFingerprint(ctx, private)
+	if diags.HasError() || planned == nil {
+		// Nothing was recorded (e.g. state from an older provider version).
+		return diags
+	}
+
+	res, err := getResourceWithSecret(ctx, r.client.Client, resourceID)
+	if err != nil {
+		diags.AddError("Unable to read resource "+resourceID, err.Error())
+		return diags
+	}
+	if err := passwordConflict(*planned, newPasswordFingerprint(res)); err != nil {
+		diags.AddError("Resource "+resourceID+" was modified outside of Terraform", err.Error())
+	}
+	return diags
+}
+
+// passwordConflict returns an error if the current revision of a secret is not
+// the planned one.
+func passwordConflict(planned passwordFingerprint, current passwordFingerprint) error {
+	if planned.matches(current) {
+		return nil
+	}
+	return fmt.Errorf("The secret was last modified at %s, but the plan was made against the version from %s. "+
+		"Run terraform apply again to plan against the current version, or set conflict_policy = \"%s\" to overwrite it.",
+		current.Modified, planned.Modified, conflictPolicyOverwrite)
+}
+
+// Values of the conflict_policy attribute.
+const (
+	conflictPolicyFail      = "fail"
+	conflictPolicyOverwrite = "overwrite"
+)
+
+// passwordFingerprintKey is the private state key holding the passwordFingerprint.
+const passwordFingerprintKey = "secret_fingerprint"
+
+// passwordFingerprint identifies the server-side revision of a resource and its
+// secret without having to decrypt it.
+type passwordFingerprint struct {
+	Modified string `json:"modified"`
+	SecretID string `json:"secret_id"`
+}
+
+// privateStateReader is implemented by the private state of framework requests.
+type privateStateReader interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// privateState is implemented by the private state of framework responses.
+type privateState interface {
+	privateStateReader
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// matches reports whether the fingerprint identifies the same revision as
+// current. Write responses may not contain the secret, so a fingerprint
+// without a secret ID is compared by its modification time only, which
+// changes with every write of the secret as well.
+func (f passwordFingerprint) matches(current passwordFingerprint) bool {
+	if f.SecretID == "" {
+		return f.Modified != "" && f.Modified == current.Modified
+	}
+	return f == current
+}
+
+func newPasswordFingerprint(res *api.Resource) passwordFingerprint {
+	var fingerprint passwordFingerprint
+	if res.Modified != nil {
+		fingerprint.Modified = res.Modified.Format(time.RFC3339)
+	}
+	if len(res.Secrets) > 0 {
+		fingerprint.SecretID = res.Secrets[0].ID
+	}
+	return fingerprint
+}
+
+func getPasswordFingerprint(ctx context.Context, private privateStateReader) (*passwordFingerprint, diag.Diagnostics) {
+	data, diags := private.GetKey(ctx, passwordFingerprintKey)
+	if diags.HasError() || len(data) == 0 {
+		return nil, diags
+	}
+
+	var fingerprint passwordFingerprint
+	if err := json.Unmarshal(data, &fingerprint); err != nil {
+		// A broken fingerprint only means we decrypt once more.
+		return nil, diags
+	}
+	return &fingerprint, diags
+}
+
+func setPasswordFingerprint(ctx context.Context, private privateState, fingerprint passwordFingerprint) diag.Diagnostics {
+	data, err := json.Marshal(fingerprint)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to encode resource fingerprint", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, passwordFingerprintKey, data)
+}
+
+// getResourceWithSecret fetches a resource together with its encrypted secret,
+// without decrypting it.
+func getResourceWithSecret(ctx context.Context, client *api.Client, resourceID string) (*api.Resource, error) {
+	resources, err := client.GetResources(ctx, &api.GetResourcesOptions{
+		FilterHasID:   []string{resourceID},
+		ContainSecret: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range resources {
+		if res.ID == resourceID && len(res.Secrets) > 0 {
+			return &res, nil
+		}
+	}
+	return nil, fmt.Errorf("resource %s not found", resourceID)
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePrivateState is an in-memory privateState.
+type fakePrivateState map[string][]byte
+
+func (p fakePrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p fakePrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+// roundTripFunc is an http.RoundTripper answering with a fixed function.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestPasswordFingerprint(t *testing.T) {
+	modified := api.Time{Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	res := &api.Resource{Modified: &modified, Secrets: []api.Secret{{ID: "s1"}}}
+
+	fingerprint := newPasswordFingerprint(res)
+	assert.Equal(t, passwordFingerprint{Modified: "2024-05-01T10:00:00Z", SecretID: "s1"}, fingerprint)
+	assert.True(t, fingerprint.matches(fingerprint))
+	assert.False(t, fingerprint.matches(passwordFingerprint{Modified: fingerprint.Modified, SecretID: "s2"}))
+
+	// Write responses without the secret are compared by modification time.
+	written := passwordFingerprint{Modified: fingerprint.Modified}
+	assert.True(t, written.matches(fingerprint))
+	assert.False(t, written.matches(passwordFingerprint{Modified: "2024-05-02T10:00:00Z", SecretID: "s1"}))
+	assert.False(t, passwordFingerprint{}.matches(passwordFingerprint{}))
+}
+
+func TestPasswordFingerprintPrivateState(t *testing.T) {
+	ctx := context.Background()
+	private := fakePrivateState{}
+
+	stored, diags := getPasswordFingerprint(ctx, private)
+	assert.False(t, diags.HasError())
+	assert.Nil(t, stored)
+
+	fingerprint := passwordFingerprint{Modified: "2024-05-01T10:00:00Z", SecretID: "s1"}
+	assert.False(t, setPasswordFingerprint(ctx, private, fingerprint).HasError())
+	stored, diags = getPasswordFingerprint(ctx, private)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, &fingerprint, stored)
+
+	// A broken fingerprint only means the secret is decrypted once more.
+	private[passwordFingerprintKey] = []byte("{")
+	stored, _ = getPasswordFingerprint(ctx, private)
+	assert.Nil(t, stored)
+}
+
+func TestStorePasswordFingerprint(t *testing.T) {
+	body := `{"header":{"status":"success"},"body":{"id":"r1","modified":"2024-05-01T10:00:00+00:00"}}`
+	transport := &loggingTransport{transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}), logCtx: newAPILogContext(context.Background())}
+
+	ctx, rec := withResponseRecorder(context.Background(), "POST", "/resources.json")
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://passbolt.example.com/resources.json", nil)
+	_, err := transport.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Nil(t, rec.body, "only matching requests are recorded")
+
+	req, _ = http.NewRequestWithContext(ctx, "POST", "https://passbolt.example.com/resources.json", nil)
+	_, err = transport.RoundTrip(req)
+	assert.NoError(t, err)
+
+	private := fakePrivateState{}
+	fingerprint, diags := storePasswordFingerprint(ctx, rec, private)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, "2024-05-01T10:00:00Z", fingerprint.Modified)
+	stored, _ := getPasswordFingerprint(ctx, private)
+	assert.Equal(t, &fingerprint, stored)
+}
+
+func TestPasswordConflict(t *testing.T) {
+	planned := passwordFingerprint{Modified: "2024-05-01T10:00:00Z", SecretID: "s1"}
+
+	assert.NoError(t, passwordConflict(planned, planned))
+
+	err := passwordConflict(planned, passwordFingerprint{Modified: "2024-05-02T08:00:00Z", SecretID: "s2"})
+	assert.ErrorContains(t, err, "last modified at 2024-05-02T08:00:00Z")
+	assert.ErrorContains(t, err, `conflict_policy = "overwrite"`)
+}
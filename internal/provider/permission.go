@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Passbolt permission types.
+const (
+	permissionDelete = -1
+	permissionRead   = 1
+	permissionUpdate = 7
+	permissionOwner  = 15
+)
+
+// permissionLevelNames maps the human-readable permission levels to Passbolt permission types.
+var permissionLevelNames = map[string]int{
+	"read":   permissionRead,
+	"update": permissionUpdate,
+	"owner":  permissionOwner,
+}
+
+// permissionLevelValues are all accepted spellings of a permission level,
+// including the numeric aliases.
+var permissionLevelValues = []string{"read", "update", "owner", "1", "7", "15"}
+
+// sharePermissionValues are the accepted values of share_permission, which
+// additionally allows -1 to delete the permission.
+var sharePermissionValues = []string{"read", "update", "owner", "1", "7", "15", "-1"}
+
+// parsePermissionLevel converts a permission level (read, update, owner or
+// their numeric aliases 1, 7, 15) to a Passbolt permission type.
+func parsePermissionLevel(level string) (int, error) {
+	if pemType, ok := permissionLevelNames[level]; ok {
+		return pemType, nil
+	}
+	pemType, err := strconv.Atoi(level)
+	if err == nil && (pemType == permissionRead || pemType == permissionUpdate || pemType == permissionOwner) {
+		return pemType, nil
+	}
+	return 0, fmt.Errorf("invalid permission level, expected one of: read, update, owner, 1, 7, 15, got input: %s", level)
+}
+
+// permissionLevelName returns the human-readable name of a Passbolt permission type.
+func permissionLevelName(pemType int) string {
+	for name, el := range permissionLevelNames {
+		if el == pemType {
+			return name
+		}
+	}
+	return strconv.Itoa(pemType)
+}
+
+// formatPermissionLevel formats a Passbolt permission type in the same form
+// (name or number) as like, so values read from the server do not cause a diff.
+func formatPermissionLevel(pemType int, like string) string {
+	if _, err := strconv.Atoi(like); err == nil {
+		return strconv.Itoa(pemType)
+	}
+	return permissionLevelName(pemType)
+}
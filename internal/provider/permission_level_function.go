@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &permissionLevelFunction{}
+)
+
+// NewPermissionLevelFunction is a helper function to simplify the provider implementation.
+func NewPermissionLevelFunction() function.Function {
+	return &permissionLevelFunction{}
+}
+
+// permissionLevelFunction is the function implementation.
+type permissionLevelFunction struct{}
+
+// Metadata returns the function name.
+func (f *permissionLevelFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "permission_level"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *permissionLevelFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Converts between named and numeric Passbolt permission levels.",
+		Description: "Returns the numeric Passbolt permission type for a named level (read: 1, update: 7, owner: 15) and the name for a numeric permission type.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "level",
+				Description: "A permission level, either: read, update, owner, 1, 7, 15",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run converts the permission level.
+func (f *permissionLevelFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var level string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &level))
+	if resp.Error != nil {
+		return
+	}
+
+	pemType, err := parsePermissionLevel(level)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		return
+	}
+
+	result := strconv.Itoa(pemType)
+	if _, err := strconv.Atoi(level); err == nil {
+		result = permissionLevelName(pemType)
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
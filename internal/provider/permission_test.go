@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePermissionLevel(t *testing.T) {
+	for level, expected := range map[string]int{"read": 1, "update": 7, "owner": 15, "1": 1, "7": 7, "15": 15} {
+		pemType, err := parsePermissionLevel(level)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, pemType, level)
+	}
+
+	_, err := parsePermissionLevel("3")
+	assert.Error(t, err)
+}
+
+func TestFormatPermissionLevel(t *testing.T) {
+	// Values read from the server keep the spelling used in the configuration.
+	assert.Equal(t, "update", formatPermissionLevel(7, "read"))
+	assert.Equal(t, "7", formatPermissionLevel(7, "1"))
+}
+
+func TestPermissionLevelValues(t *testing.T) {
+	// Every accepted spelling maps to its Passbolt permission type.
+	expected := map[string]int{"read": 1, "update": 7, "owner": 15, "1": 1, "7": 7, "15": 15}
+	assert.ElementsMatch(t, []string{"read", "update", "owner", "1", "7", "15"}, permissionLevelValues)
+	for _, el := range permissionLevelValues {
+		pemType, err := parsePermissionLevel(el)
+		assert.NoError(t, err, el)
+		assert.Equal(t, expected[el], pemType, el)
+	}
+
+	// Names and numbers convert back to the same spelling.
+	assert.Equal(t, "read", permissionLevelName(1))
+	assert.Equal(t, "update", permissionLevelName(7))
+	assert.Equal(t, "owner", permissionLevelName(15))
+	assert.Equal(t, "15", formatPermissionLevel(15, "7"))
+
+	// Shares additionally accept -1 to delete the permission.
+	assert.ElementsMatch(t, append([]string{"-1"}, permissionLevelValues...), sharePermissionValues)
+}
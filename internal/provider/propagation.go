@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+)
+
+// folderChild is a folder or resource somewhere below a folder.
+type folderChild struct {
+	Type        string
+	ID          string
+	Name        string
+	Permissions []api.Permission
+}
+
+// String identifies the child in drift reports and error messages.
+func (c folderChild) String() string {
+	return fmt.Sprintf("%s %s (%s)", c.Type, c.Name, c.ID)
+}
+
+// getFolderChildren returns all folders and resources below folderID, including
+// those in sub-folders, together with their permissions.
+func getFolderChildren(ctx context.Context, client *api.Client, folderID string) ([]folderChild, error) {
+	children := make([]folderChild, 0)
+	parents := []string{folderID}
+	for len(parents) > 0 {
+		folders, err := client.GetFolders(ctx, &api.GetFoldersOptions{
+			FilterHasParent:    parents,
+			ContainPermissions: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sub-folders of %v: %w", parents, err)
+		}
+		resources, err := getResourcesWithPermissions(ctx, client, parents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get resources of %v: %w", parents, err)
+		}
+
+		for _, res := range resources {
+			children = append(children, folderChild{Type: shareTypeResource, ID: res.ID, Name: res.Name, Permissions: res.Permissions})
+		}
+
+		parents = make([]string, 0, len(folders))
+		for _, folder := range folders {
+			children = append(children, folderChild{Type: shareTypeFolder, ID: folder.ID, Name: folder.Name, Permissions: folder.Permissions})
+			parents = append(parents, folder.ID)
+		}
+	}
+	return children, nil
+}
+
+// getResourcesOptions are the query parameters to list resources which
+// api.GetResourcesOptions does not support: a search term, and all
+// permissions instead of only the one of the acting user.
+type getResourcesOptions struct {
+	FilterSearch       string   `url:"filter[search],omitempty"`
+	FilterHasParent    []string `url:"filter[has-parent][],omitempty"`
+	ContainPermissions bool     `url:"contain[permissions],omitempty"`
+}
+
+// resourceWithPermissions is a resource including the permissions of all users and groups.
+type resourceWithPermissions struct {
+	api.Resource
+	Permissions []api.Permission `json:"permissions,omitempty"`
+}
+
+// searchResources returns the resources matching opts. The search filter also
+// matches resources which only contain the search term.
+func searchResources(ctx context.Context, client *api.Client, opts *getResourcesOptions) ([]api.Resource, error) {
+	msg, err := client.DoCustomRequest(ctx, "GET", "/resources.json", "v2", nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []api.Resource
+	if err := json.Unmarshal(msg.Body, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// getResourcesWithPermissions returns the resources in the given folders with
+// their permissions in a single request.
+func getResourcesWithPermissions(ctx context.Context, client *api.Client, parents []string) ([]resourceWithPermissions, error) {
+	opts := &getResourcesOptions{
+		FilterHasParent:    parents,
+		ContainPermissions: true,
+	}
+	msg, err := client.DoCustomRequest(ctx, "GET", "/resources.json", "v2", nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []resourceWithPermissions
+	if err := json.Unmarshal(msg.Body, &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// missingGrants returns the grants which are not yet applied to the given
+// permissions. A grant with Type -1 is missing as long as the permission exists.
+func missingGrants(permissions []api.Permission, grants []helper.ShareOperation) []helper.ShareOperation {
+	missing := make([]helper.ShareOperation, 0)
+	for _, grant := range grants {
+		var existing *api.Permission
+		for i := range permissions {
+			if permissions[i].ARO == grant.ARO && permissions[i].AROForeignKey == grant.AROID {
+				existing = &permissions[i]
+				break
+			}
+		}
+
+		if grant.Type == permissionDelete {
+			if existing != nil {
+				missing = append(missing, grant)
+			}
+		} else if existing == nil || existing.Type != grant.Type {
+			missing = append(missing, grant)
+		}
+	}
+	return missing
+}
+
+// getPropagationDrift returns the children of folderID which are missing any of the grants.
+func getPropagationDrift(ctx context.Context, client *api.Client, folderID string, grants []helper.ShareOperation) ([]string, error) {
+	children, err := getFolderChildren(ctx, client, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := make([]string, 0)
+	for _, child := range children {
+		if len(missingGrants(child.Permissions, grants)) > 0 {
+			drift = append(drift, child.String())
+		}
+	}
+	return drift, nil
+}
+
+// propagateGrants applies the grants to all folders and resources below
+// folderID. Secrets of resources are encrypted for new users and group members.
+func propagateGrants(ctx context.Context, client *PassboltClient, folderID string, grants []helper.ShareOperation) error {
+	children, err := getFolderChildren(ctx, client.Client, folderID)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		missing := missingGrants(child.Permissions, grants)
+		if len(missing) == 0 {
+			continue
+		}
+
+		if err := client.checkShareLockout(ctx, child.Permissions, missing); err != nil {
+			return fmt.Errorf("failed to propagate permissions to %s: %w", child, err)
+		}
+		if child.Type == shareTypeResource {
+			err = helper.ShareResource(ctx, client.Client, child.ID, missing)
+		} else {
+			err = helper.ShareFolder(ctx, client.Client, child.ID, missing)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to propagate permissions to %s: %w", child, err)
+		}
+	}
+	return nil
+}
+
+// stringListValue converts values to a types.List of strings.
+func stringListValue(values []string) types.List {
+	elems := make([]attr.Value, 0, len(values))
+	for _, el := range values {
+		elems = append(elems, types.StringValue(el))
+	}
+	return types.ListValueMust(types.StringType, elems)
+}
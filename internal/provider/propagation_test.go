@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingGrants(t *testing.T) {
+	permissions := []api.Permission{
+		{ARO: "User", AROForeignKey: "u1", Type: 15},
+		{ARO: "Group", AROForeignKey: "g1", Type: 1},
+	}
+	grants := []helper.ShareOperation{
+		{ARO: "User", AROID: "u1", Type: 15},
+		{ARO: "Group", AROID: "g1", Type: 7},
+		{ARO: "Group", AROID: "g2", Type: 1},
+		{ARO: "User", AROID: "u2", Type: -1},
+	}
+
+	assert.Equal(t, []helper.ShareOperation{
+		{ARO: "Group", AROID: "g1", Type: 7},
+		{ARO: "Group", AROID: "g2", Type: 1},
+	}, missingGrants(permissions, grants))
+}
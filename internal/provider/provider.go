@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -18,12 +19,18 @@ type PassboltClient struct {
 	Url        string
 	PrivateKey string
 	Password   string
-	Context    context.Context
+	// VerifySecretOnRefresh forces secrets to be downloaded and decrypted on
+	// every refresh, even if the resource is unchanged on the server.
+	VerifySecretOnRefresh bool
+	// AllowSelfDowngrade allows share and permission changes which reduce the
+	// acting user's own access to a folder or resource.
+	AllowSelfDowngrade bool
 }
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &passboltProvider{}
+	_ provider.Provider              = &passboltProvider{}
+	_ provider.ProviderWithFunctions = &passboltProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -47,6 +54,9 @@ type passboltProviderModel struct {
 	URL  types.String `tfsdk:"base_url"`
 	KEY  types.String `tfsdk:"private_key"`
 	PASS types.String `tfsdk:"passphrase"`
+
+	VerifySecretOnRefresh types.Bool `tfsdk:"verify_secret_on_refresh"`
+	AllowSelfDowngrade    types.Bool `tfsdk:"allow_self_downgrade"`
 }
 
 // Metadata returns the provider type name.
@@ -73,6 +83,14 @@ func (p *passboltProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"verify_secret_on_refresh": schema.BoolAttribute{
+				Description: "Always download and decrypt secrets when refreshing `passbolt_password` resources. By default secrets are only decrypted again when the resource's `modified` timestamp or secret ID changed on the server.",
+				Optional:    true,
+			},
+			"allow_self_downgrade": schema.BoolAttribute{
+				Description: "Allow share and permission changes which remove or downgrade the provider user's own access to a folder or resource. By default such changes are refused, so the provider cannot lock itself out.",
+				Optional:    true,
+			},
 		},
 	}
 }
@@ -135,7 +153,7 @@ func (p *passboltProvider) Configure(ctx context.Context, req provider.Configure
 		return
 	}
 
-	client, err := api.NewClient(nil, "", url, key, pass)
+	client, err := api.NewClient(newLoggingHTTPClient(newAPILogContext(ctx)), "", url, key, pass)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to connect to passbolt",
@@ -147,12 +165,14 @@ func (p *passboltProvider) Configure(ctx context.Context, req provider.Configure
 	passboltClient := PassboltClient{
 		Client:     client,
 		Url:        url,
-		Context:    context.TODO(),
 		Password:   pass,
 		PrivateKey: key,
+
+		VerifySecretOnRefresh: config.VerifySecretOnRefresh.ValueBool(),
+		AllowSelfDowngrade:    config.AllowSelfDowngrade.ValueBool(),
 	}
 	if p.version != "test" {
-		err = passboltClient.Client.Login(passboltClient.Context)
+		err = passboltClient.Client.Login(ctx)
 		if err != nil {
 			resp.Diagnostics.AddAttributeError(
 				path.Root("url"),
@@ -187,5 +207,15 @@ func (p *passboltProvider) Resources(_ context.Context) []func() resource.Resour
 		NewShareResource,
 		NewUserResource,
 		NewGroupResource,
+		NewFolderPermissionsResource,
+		NewGroupMembershipResource,
+		NewServiceAccountResource,
+	}
+}
+
+// Functions defines the provider functions implemented in the provider.
+func (p *passboltProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewPermissionLevelFunction,
 	}
 }
@@ -87,7 +87,7 @@ func (d *roleDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	var state roleDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
 
-	roles, err := d.client.Client.GetRoles(d.client.Context)
+	roles, err := d.client.Client.GetRoles(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read roles", "",
@@ -0,0 +1,450 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	pgpcrypto "github.com/ProtonMail/gopenpgp/v2/crypto"
+	pgphelper "github.com/ProtonMail/gopenpgp/v2/helper"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/passbolt/go-passbolt/helper"
+)
+
+// serviceAccountKeyBits is the size of the generated RSA keys.
+const serviceAccountKeyBits = 4096
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = &serviceAccountResource{}
+	_ resource.ResourceWithConfigure  = &serviceAccountResource{}
+	_ resource.ResourceWithModifyPlan = &serviceAccountResource{}
+)
+
+// NewServiceAccountResource is a helper function to simplify the provider implementation.
+func NewServiceAccountResource() resource.Resource {
+	return &serviceAccountResource{}
+}
+
+// serviceAccountResource is the resource implementation.
+type serviceAccountResource struct {
+	client *PassboltClient
+}
+
+type serviceAccountModel struct {
+	ID          types.String `tfsdk:"id"`
+	UserName    types.String `tfsdk:"username"`
+	FirstName   types.String `tfsdk:"firstname"`
+	LastName    types.String `tfsdk:"lastname"`
+	Role        types.String `tfsdk:"role"`
+	RoleID      types.String `tfsdk:"role_id"`
+	InviteURL   types.String `tfsdk:"invite_url"`
+	Active      types.Bool   `tfsdk:"active"`
+	PrivateKey  types.String `tfsdk:"private_key"`
+	PublicKey   types.String `tfsdk:"public_key"`
+	Fingerprint types.String `tfsdk:"fingerprint"`
+	Passphrase  types.String `tfsdk:"passphrase"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *serviceAccountResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*PassboltClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *passboltClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *serviceAccountResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_account"
+}
+
+// Schema defines the schema for the resource.
+func (r *serviceAccountResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A Passbolt user for machines. The OpenPGP key pair of the account is generated by the provider and the account setup is completed through the API, " +
+			"as soon as the invitation URL is known. Passbolt only hands out the setup token in the invitation email or through the `register_user` command on the server. " +
+			"Until the setup is completed, `active` is false and every plan warns about it. " +
+			"The provider cannot complete the setup on its own: without `invite_url` only a pending user is created, which cannot log in.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The user id.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"username": schema.StringAttribute{
+				Description: "The user name. This needs to be a valid email. The account will be replaced upon username change.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"firstname": schema.StringAttribute{
+				Description: "The first name of the user.",
+				Required:    true,
+			},
+			"lastname": schema.StringAttribute{
+				Description: "The last name of the user.",
+				Required:    true,
+			},
+			"role": schema.StringAttribute{
+				Description: "The name of the user role, either: admin, user or the name of a custom role. Defaults to the server default, usually user.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_id": schema.StringAttribute{
+				Description: "The ID of the user role.",
+				Computed:    true,
+			},
+			"invite_url": schema.StringAttribute{
+				Description: "The invitation URL of the account, of the form https://<server>/setup/install/<user_id>/<token>. " +
+					"If set on creation, the already registered user of the URL is set up instead of creating a new one. " +
+					"Otherwise the user is created and the setup is completed once the URL from the invitation email is set.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"active": schema.BoolAttribute{
+				Description: "Whether the account setup is completed.",
+				Computed:    true,
+			},
+			"private_key": schema.StringAttribute{
+				Description: "The armored OpenPGP private key of the account.",
+				Computed:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Description: "The armored OpenPGP public key of the account.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"fingerprint": schema.StringAttribute{
+				Description: "The fingerprint of the OpenPGP key of the account.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"passphrase": schema.StringAttribute{
+				Description: "The passphrase of the private key.",
+				Computed:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// ModifyPlan resolves the role and reminds of accounts whose setup is not completed yet.
+func (r *serviceAccountResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if r.client == nil || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan serviceAccountModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planRoleID(ctx, r.client.Client, plan.Role, resp)
+
+	if !req.State.Raw.IsNull() && plan.InviteURL.IsNull() {
+		var state serviceAccountModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if !state.Active.IsNull() && !state.Active.ValueBool() {
+			resp.Diagnostics.AddWarning(setupIncompleteWarning(state.UserName.ValueString()))
+		}
+	}
+}
+
+// setupIncompleteWarning explains that the account cannot be used before the
+// setup is completed with the token from the invitation email.
+func setupIncompleteWarning(username string) (string, string) {
+	return fmt.Sprintf("Setup of service account %s is not completed", username),
+		"The user exists, but cannot sign in with the generated key until its setup is completed. " +
+			"Passbolt only hands out the setup token in the invitation email or through the register_user command on the server. " +
+			"Set invite_url to the invitation URL and apply again to complete the setup."
+}
+
+// Create a new resource.
+func (r *serviceAccountResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan serviceAccountModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Generate the key first, so a failure does not leave a user behind.
+	key, err := generateAccountKey(
+		plan.FirstName.ValueString()+" "+plan.LastName.ValueString(),
+		plan.UserName.ValueString(),
+		serviceAccountKeyBits,
+	)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to generate OpenPGP key", err.Error())
+		return
+	}
+	plan.PrivateKey = types.StringValue(key.privateKey)
+	plan.PublicKey = types.StringValue(key.publicKey)
+	plan.Fingerprint = types.StringValue(key.fingerprint)
+	plan.Passphrase = types.StringValue(key.passphrase)
+
+	var userID, token string
+	if !plan.InviteURL.IsNull() {
+		userID, token, err = helper.ParseInviteUrl(plan.InviteURL.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("invite_url"), "Invalid invite URL", err.Error())
+			return
+		}
+		install, err := r.client.Client.SetupInstall(ctx, userID, token)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to start the setup of user: %s", userID), err.Error())
+			return
+		}
+		if install.Username != plan.UserName.ValueString() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("invite_url"),
+				"Invite URL of another user",
+				fmt.Sprintf("The invite URL belongs to %s, not to %s.", install.Username, plan.UserName.ValueString()),
+			)
+			return
+		}
+	} else {
+		user, diags := newAPIUser(ctx, r.client.Client, plan.UserName.ValueString(), plan.FirstName.ValueString(), plan.LastName.ValueString(), plan.Role)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		cUser, err := r.client.Client.CreateUser(ctx, user)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to create user of name: %s", user.Username), err.Error())
+			return
+		}
+		userID = cUser.ID
+	}
+	plan.ID = types.StringValue(userID)
+
+	if token != "" {
+		if err := completeAccountSetup(ctx, r.client.Client, userID, token, key.publicKey); err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to complete the setup of user: %s", userID), err.Error())
+			return
+		}
+		// The user was registered outside of terraform, apply the configured profile and role.
+		resp.Diagnostics.Append(r.updateUser(ctx, userID, plan)...)
+	} else {
+		resp.Diagnostics.AddWarning(setupIncompleteWarning(plan.UserName.ValueString()))
+	}
+
+	resp.Diagnostics.Append(r.refresh(ctx, &plan)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *serviceAccountResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state serviceAccountModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user, err := getUserDetails(ctx, r.client.Client, state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Cannot get user: %s", state.ID.ValueString()), err.Error())
+		return
+	}
+	if user == nil || user.Deleted {
+		// The user was deleted outside of terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	setServiceAccountUser(&state, user)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *serviceAccountResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state serviceAccountModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.updateUser(ctx, state.ID.ValueString(), plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The invitation URL completes the setup of a user created by an earlier apply.
+	if !state.Active.ValueBool() && !plan.InviteURL.IsNull() && !plan.InviteURL.Equal(state.InviteURL) {
+		userID, token, err := helper.ParseInviteUrl(plan.InviteURL.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("invite_url"), "Invalid invite URL", err.Error())
+			return
+		}
+		if userID != state.ID.ValueString() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("invite_url"),
+				"Invite URL of another user",
+				fmt.Sprintf("The invite URL belongs to the user with ID %s, not to %s.", userID, state.ID.ValueString()),
+			)
+			return
+		}
+		if err := completeAccountSetup(ctx, r.client.Client, userID, token, state.PublicKey.ValueString()); err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to complete the setup of user: %s", userID), err.Error())
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.refresh(ctx, &plan)...)
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *serviceAccountResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state serviceAccountModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.Client.DeleteUser(ctx, state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to delete user with ID: %s", state.ID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+}
+
+// updateUser updates the profile and role of the user.
+func (r *serviceAccountResource) updateUser(ctx context.Context, userID string, plan serviceAccountModel) diag.Diagnostics {
+	user, diags := newAPIUser(ctx, r.client.Client, plan.UserName.ValueString(), plan.FirstName.ValueString(), plan.LastName.ValueString(), plan.Role)
+	if diags.HasError() {
+		return diags
+	}
+	if _, err := r.client.Client.UpdateUser(ctx, userID, user); err != nil {
+		diags.AddError(fmt.Sprintf("failed to update user of name: %s", user.Username), err.Error())
+	}
+	return diags
+}
+
+// refresh populates the computed attributes of model from the user on the server.
+func (r *serviceAccountResource) refresh(ctx context.Context, model *serviceAccountModel) (diags diag.Diagnostics) {
+	user, err := getUserDetails(ctx, r.client.Client, model.ID.ValueString())
+	if err != nil || user == nil {
+		diags.AddError(fmt.Sprintf("Cannot get user: %s", model.ID.ValueString()), userLookupError(err))
+		model.Active = types.BoolValue(false)
+		if model.Role.IsUnknown() {
+			model.Role = types.StringNull()
+		}
+		model.RoleID = types.StringNull()
+		return diags
+	}
+	setServiceAccountUser(model, user)
+	return diags
+}
+
+// setServiceAccountUser sets the attributes of model which are read from the user.
+func setServiceAccountUser(model *serviceAccountModel, user *userDetails) {
+	model.UserName = types.StringValue(user.Username)
+	if user.Profile != nil {
+		model.FirstName = types.StringValue(user.Profile.FirstName)
+		model.LastName = types.StringValue(user.Profile.LastName)
+	}
+	// A role configured by its ID is kept as is, Terraform rejects a state
+	// which differs from the configuration. Names are read from the server.
+	if user.Role != nil && model.Role.ValueString() != user.Role.ID {
+		model.Role = types.StringValue(user.Role.Name)
+	}
+	if user.Role != nil {
+		model.RoleID = types.StringValue(user.Role.ID)
+	}
+	model.Active = types.BoolValue(user.Active)
+}
+
+// accountKey is a generated OpenPGP key pair.
+type accountKey struct {
+	privateKey  string
+	publicKey   string
+	fingerprint string
+	passphrase  string
+}
+
+// generateAccountKey generates an RSA key pair for the account, protected by a random passphrase.
+func generateAccountKey(name string, email string, bits int) (*accountKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate passphrase: %w", err)
+	}
+	passphrase := base64.RawURLEncoding.EncodeToString(secret)
+
+	privateKey, err := pgphelper.GenerateKey(name, email, []byte(passphrase), "rsa", bits)
+	if err != nil {
+		return nil, err
+	}
+	key, err := pgpcrypto.NewKeyFromArmoredReader(strings.NewReader(privateKey))
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &accountKey{
+		privateKey:  privateKey,
+		publicKey:   publicKey,
+		fingerprint: strings.ToUpper(key.GetFingerprint()),
+		passphrase:  passphrase,
+	}, nil
+}
+
+// completeAccountSetup registers the public key for the user and activates it.
+func completeAccountSetup(ctx context.Context, client *api.Client, userID string, token string, publicKey string) error {
+	return client.SetupComplete(ctx, userID, api.SetupCompleteRequest{
+		AuthenticationToken: api.AuthenticationToken{Token: token},
+		User:                api.User{Locale: api.UserLocaleENUK},
+		GPGKey:              api.GPGKey{ArmoredKey: publicKey},
+	})
+}
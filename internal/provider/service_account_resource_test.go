@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	pgpcrypto "github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAccountKey(t *testing.T) {
+	key, err := generateAccountKey("CI Bot", "ci@example.com", 2048)
+	assert.NoError(t, err)
+
+	private, err := pgpcrypto.NewKeyFromArmored(key.privateKey)
+	assert.NoError(t, err)
+	locked, err := private.IsLocked()
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	// The passphrase unlocks the private key.
+	_, err = private.Unlock([]byte(key.passphrase))
+	assert.NoError(t, err)
+
+	public, err := pgpcrypto.NewKeyFromArmored(key.publicKey)
+	assert.NoError(t, err)
+	assert.False(t, public.IsPrivate())
+	assert.Equal(t, strings.ToUpper(public.GetFingerprint()), key.fingerprint)
+}
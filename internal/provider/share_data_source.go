@@ -128,7 +128,7 @@ func (d *shareDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	if state.ID.String() != "" {
 		opts = api.SearchAROsOptions{FilterSearch: state.ID.String()}
 	}
-	shares, err := d.client.Client.SearchAROs(d.client.Context, opts)
+	shares, err := d.client.Client.SearchAROs(ctx, opts)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read shares", "",
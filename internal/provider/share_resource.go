@@ -2,21 +2,31 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/passbolt/go-passbolt/api"
 	"github.com/passbolt/go-passbolt/helper"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &shareResource{}
-	_ resource.ResourceWithConfigure = &shareResource{}
+	_ resource.Resource                   = &shareResource{}
+	_ resource.ResourceWithConfigure      = &shareResource{}
+	_ resource.ResourceWithValidateConfig = &shareResource{}
 )
 
 // NewShareResource is a helper function to simplify the provider implementation.
@@ -29,13 +39,45 @@ type shareResource struct {
 	client *PassboltClient
 }
 
+// Values of the type attribute.
+const (
+	shareTypeFolder   = "folder"
+	shareTypeResource = "resource"
+)
+
 // sharesResourceData create request
 type sharesResourceData struct {
+	ID               types.String `tfsdk:"id"`
+	PermissionID     types.String `tfsdk:"permission_id"`
 	Name             types.String `tfsdk:"name"`
+	FolderID         types.String `tfsdk:"folder_id"`
+	ResourceID       types.String `tfsdk:"resource_id"`
 	Type             types.String `tfsdk:"type"`
 	ShareTargetType  types.String `tfsdk:"share_target_type"`
 	ShareTargetValue types.String `tfsdk:"share_target_value"`
+	AROID            types.String `tfsdk:"aro_id"`
 	SharePermission  types.String `tfsdk:"share_permission"`
+	Propagate        types.Bool   `tfsdk:"propagate"`
+	PropagationDrift types.List   `tfsdk:"propagation_drift"`
+}
+
+// objectRef describes the shared folder or resource for error messages.
+func (d sharesResourceData) objectRef() string {
+	switch {
+	case !d.FolderID.IsNull():
+		return "folder: " + d.FolderID.ValueString()
+	case !d.ResourceID.IsNull():
+		return "resource: " + d.ResourceID.ValueString()
+	}
+	return d.Type.ValueString() + ": " + d.Name.ValueString()
+}
+
+// targetRef describes the share target for error messages.
+func (d sharesResourceData) targetRef() string {
+	if !d.AROID.IsNull() {
+		return fmt.Sprintf("share-target: %s, share-id: %s", d.ShareTargetType.ValueString(), d.AROID.ValueString())
+	}
+	return fmt.Sprintf("share-target: %s, share-value: %s", d.ShareTargetType.ValueString(), d.ShareTargetValue.ValueString())
 }
 
 // Configure adds the provider configured client to the resource.
@@ -67,31 +109,136 @@ func (r *shareResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 	resp.Schema = schema.Schema{
 		Description: "A Passbolt Share Resource.",
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the share, composed of the ID of the shared folder or resource and the ID of the share target.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"permission_id": schema.StringAttribute{
+				Description: "The ID of the permission granted by this share. Empty if `share_permission` is -1.",
+				Computed:    true,
+			},
 			"name": schema.StringAttribute{
-				Description: "The name of the resource to share",
-				Required:    true,
+				Description: "The name of the resource to share. Conflicts with `folder_id` and `resource_id`.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"folder_id": schema.StringAttribute{
+				Description: "The ID of the folder to share. Conflicts with `name` and `resource_id`.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"resource_id": schema.StringAttribute{
+				Description: "The ID of the resource to share. Conflicts with `name` and `folder_id`.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"type": schema.StringAttribute{
-				Description: "The type of the resource to share, either: resource, folder",
+				Description: "The type of the resource to share, either: resource, folder. Defaults to resource if `resource_id` is set, folder otherwise.",
+				Optional:    true,
 				Computed:    true,
-				Default:     stringdefault.StaticString("folder"),
+				Validators: []validator.String{
+					stringOneOf(shareTypeFolder, shareTypeResource),
+				},
+				PlanModifiers: []planmodifier.String{
+					shareTypeDefault{},
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"share_target_type": schema.StringAttribute{
 				Description: "The type of the share target, either: User, Group",
 				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"share_target_value": schema.StringAttribute{
-				Description: "The name-value of the share target. Looks up users username/email or groups name",
-				Required:    true,
+				Description: "The name-value of the share target. Looks up users username/email or groups name. Conflicts with `aro_id`.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"aro_id": schema.StringAttribute{
+				Description: "The ID of the user or group to share with. Conflicts with `share_target_value`.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"share_permission": schema.StringAttribute{
-				Description: "The share permission to apply, either: Read: 1, Update: 7, Owner: 15, Delete: -1",
+				Description: "The share permission to apply, either: read, update, owner or the numeric aliases 1, 7, 15. Use -1 to delete the permission. The permission read from the server is stored in the same form as configured.",
 				Required:    true,
+				Validators: []validator.String{
+					stringOneOf(sharePermissionValues...),
+				},
+			},
+			"propagate": schema.BoolAttribute{
+				Description: "Whether to apply the permission to all sub-folders and resources of the shared folder as well. Only valid for folders.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"propagation_drift": schema.ListAttribute{
+				Description: "The sub-folders and resources which are missing the propagated permission. Only populated if `propagate` is set.",
+				ElementType: types.StringType,
+				Computed:    true,
+				Default:     listdefault.StaticValue(stringListValue(nil)),
 			},
 		},
 	}
 }
 
+// ValidateConfig ensures the shared object and the share target are each referenced exactly once.
+func (r *shareResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data sharesResourceData
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	objectRefs := 0
+	for _, el := range []types.String{data.Name, data.FolderID, data.ResourceID} {
+		if !el.IsNull() {
+			objectRefs++
+		}
+	}
+	if objectRefs != 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Invalid share object",
+			"Exactly one of name, folder_id or resource_id must be set.",
+		)
+	}
+
+	if data.ShareTargetValue.IsNull() == data.AROID.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("share_target_value"),
+			"Invalid share target",
+			"Exactly one of share_target_value or aro_id must be set.",
+		)
+	}
+
+	if !data.FolderID.IsNull() && !data.Type.IsNull() && data.Type.ValueString() != shareTypeFolder {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "Invalid share type", "folder_id can only be used with type = \"folder\".")
+	}
+	if !data.ResourceID.IsNull() && !data.Type.IsNull() && data.Type.ValueString() != shareTypeResource {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "Invalid share type", "resource_id can only be used with type = \"resource\".")
+	}
+	if data.Propagate.ValueBool() && (!data.ResourceID.IsNull() || data.Type.ValueString() == shareTypeResource) {
+		resp.Diagnostics.AddAttributeError(path.Root("propagate"), "Invalid propagate", "propagate can only be used when sharing folders.")
+	}
+}
+
 // Create a new resource.
 func (r *shareResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -102,10 +249,31 @@ func (r *shareResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	if err := r.setPermission(ctx, plan); err != nil {
+	objectID, share, err := r.setPermission(ctx, plan)
+	if err != nil {
 		resp.Diagnostics.AddError("Failed to share resource", err.Error())
 		return
 	}
+	if plan.Propagate.ValueBool() {
+		if err := propagateGrants(ctx, r.client, objectID, []helper.ShareOperation{share}); err != nil {
+			resp.Diagnostics.AddError("Failed to propagate share", err.Error())
+			return
+		}
+	}
+	plan.PropagationDrift = stringListValue(nil)
+
+	plan.ID = types.StringValue(shareID(objectID, share.AROID))
+	plan.PermissionID = types.StringNull()
+	if share.Type != permissionDelete {
+		pem, err := r.getPermissionEntry(ctx, plan)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to lookup permission, %s, %s", plan.objectRef(), plan.targetRef()), err.Error())
+			return
+		}
+		if pem != nil {
+			plan.PermissionID = types.StringValue(pem.ID)
+		}
+	}
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -127,13 +295,52 @@ func (r *shareResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 
 	// read folders permission
-	pem, err := r.getPermissionEntry(ctx, data.Name.ValueString(), data.ShareTargetType.ValueString(), data.ShareTargetValue.ValueString())
+	pem, err := r.getPermissionEntry(ctx, data)
+	if errors.Is(err, errShareObjectNotFound) {
+		// The folder or resource was deleted outside of terraform, and its permissions with it.
+		tflog.Warn(ctx, "Shared object no longer exists, removing from state", map[string]interface{}{
+			"object": data.objectRef(),
+			"target": data.targetRef(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(fmt.Sprintf("failed to lookup permission, folder: %s, share-target: %s, share-value: %s", data.Name.ValueString(), data.ShareTargetType.ValueString(), data.ShareTargetValue.ValueString()), err.Error())
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to lookup permission, %s, %s", data.objectRef(), data.targetRef()), err.Error())
 		return
 	}
-	if pem != nil {
-		data.SharePermission = types.StringValue(fmt.Sprintf("%d", pem.Type))
+	if pem == nil {
+		if data.SharePermission.ValueString() != "-1" {
+			// The permission was revoked outside of terraform, plan to share again.
+			tflog.Warn(ctx, "Shared permission no longer exists, removing from state", map[string]interface{}{
+				"object": data.objectRef(),
+				"target": data.targetRef(),
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		data.PermissionID = types.StringNull()
+	} else {
+		data.ID = types.StringValue(shareID(pem.ACOForeignKey, pem.AROForeignKey))
+		data.PermissionID = types.StringValue(pem.ID)
+		// Keep the configured spelling: share_permission is not computed, so a
+		// normalised name would show a diff for every numeric alias.
+		data.SharePermission = types.StringValue(formatPermissionLevel(pem.Type, data.SharePermission.ValueString()))
+	}
+
+	drift := make([]string, 0)
+	if data.Propagate.ValueBool() && pem != nil {
+		drift, err = getPropagationDrift(ctx, r.client.Client, pem.ACOForeignKey, []helper.ShareOperation{
+			{Type: pem.Type, ARO: pem.ARO, AROID: pem.AROForeignKey},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to check propagated permissions, %s, %s", data.objectRef(), data.targetRef()), err.Error())
+			return
+		}
+	}
+	data.PropagationDrift = stringListValue(drift)
+	if data.Propagate.IsNull() {
+		data.Propagate = types.BoolValue(false)
 	}
 
 	diags = resp.State.Set(ctx, &data)
@@ -143,18 +350,55 @@ func (r *shareResource) Read(ctx context.Context, req resource.ReadRequest, resp
 // Update updates the resource and sets the updated Terraform state on success.
 func (r *shareResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data sharesResourceData
+	var state sharesResourceData
 
 	diags := req.Plan.Get(ctx, &data)
 	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if err := r.setPermission(ctx, data); err != nil {
+	data.ID = state.ID
+	data.PermissionID = state.PermissionID
+
+	var objectID string
+	var share helper.ShareOperation
+	var err error
+	switch {
+	case data.SharePermission.Equal(state.SharePermission):
+		// Only the propagation changed, the permission itself is up to date.
+		objectID, share, err = r.getShareOperation(ctx, data)
+	case !state.PermissionID.IsNull():
+		objectID, share, err = r.updatePermission(ctx, data)
+	default:
+		objectID, share, err = r.setPermission(ctx, data)
+	}
+	if err != nil {
 		resp.Diagnostics.AddError("Failed to update share resource", err.Error())
 		return
 	}
+	if data.Propagate.ValueBool() {
+		if err := propagateGrants(ctx, r.client, objectID, []helper.ShareOperation{share}); err != nil {
+			resp.Diagnostics.AddError("Failed to propagate share", err.Error())
+			return
+		}
+	}
+	data.PropagationDrift = stringListValue(nil)
+
+	data.PermissionID = types.StringNull()
+	if share.Type != permissionDelete {
+		pem, err := r.getPermissionEntry(ctx, data)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to lookup permission, %s, %s", data.objectRef(), data.targetRef()), err.Error())
+			return
+		}
+		if pem != nil {
+			data.PermissionID = types.StringValue(pem.ID)
+		}
+	}
 
 	diags = resp.State.Set(ctx, &data)
 	resp.Diagnostics.Append(diags...)
@@ -171,27 +415,70 @@ func (r *shareResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	pem, err := r.getPermissionEntry(ctx, data.Name.ValueString(), data.ShareTargetType.ValueString(), data.ShareTargetValue.ValueString())
+	pem, err := r.getPermissionEntry(ctx, data)
+	if errors.Is(err, errShareObjectNotFound) {
+		// The folder or resource was deleted outside of terraform, and its permissions with it.
+		tflog.Warn(ctx, "Shared object no longer exists, removing from state", map[string]interface{}{
+			"object": data.objectRef(),
+			"target": data.targetRef(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(fmt.Sprintf("failed to lookup permission, folder: %s, share-target: %s, share-value: %s", data.Name.ValueString(), data.ShareTargetType.ValueString(), data.ShareTargetValue.ValueString()), err.Error())
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to lookup permission, %s, %s", data.objectRef(), data.targetRef()), err.Error())
 		return
 	}
 	if pem == nil {
 		// permission already deleted
 		return
 	}
-	if data.Type.ValueString() == "folder" {
-		pem.Delete = true
-		err := r.client.Client.ShareFolder(ctx, pem.ACOForeignKey, []api.Permission{*pem})
+	revoke := helper.ShareOperation{Type: permissionDelete, ARO: pem.ARO, AROID: pem.AROForeignKey}
+	if err := r.checkLockout(ctx, data, pem.ACOForeignKey, revoke); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to delete permission, %s, %s", data.objectRef(), data.targetRef()), err.Error())
+		return
+	}
+	pem.Delete = true
+	if err := r.savePermission(ctx, data, *pem); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("failed to delete permission, %s, %s", data.objectRef(), data.targetRef()), err.Error())
+		return
+	}
+	if data.Propagate.ValueBool() && data.Type.ValueString() == shareTypeFolder {
+		err = propagateGrants(ctx, r.client, pem.ACOForeignKey, []helper.ShareOperation{revoke})
 		if err != nil {
-			resp.Diagnostics.AddError(fmt.Sprintf("failed to delete permission, folder: %s, share-target: %s, share-value: %s", data.Name.ValueString(), data.ShareTargetType.ValueString(), data.ShareTargetValue.ValueString()), err.Error())
+			resp.Diagnostics.AddError(fmt.Sprintf("failed to delete propagated permissions, %s, %s", data.objectRef(), data.targetRef()), err.Error())
 			return
 		}
 	}
 }
 
-func (r *shareResource) getAllFolders(ctx context.Context, search string) ([]api.Folder, error) {
-	return r.client.Client.GetFolders(ctx, &api.GetFoldersOptions{FilterSearch: search, ContainPermission: true, ContainPermissions: true, ContainPermissionUserProfile: true, ContainPermissionGroup: true})
+func (r *shareResource) getAllFolders(ctx context.Context, name string) ([]api.Folder, error) {
+	folders, err := r.client.Client.GetFolders(ctx, &api.GetFoldersOptions{FilterSearch: name, ContainPermission: true, ContainPermissions: true, ContainPermissionUserProfile: true, ContainPermissionGroup: true})
+	if err != nil {
+		return nil, err
+	}
+	// FilterSearch also matches folders whose name only contains the search term.
+	matches := make([]api.Folder, 0)
+	for _, el := range folders {
+		if el.Name == name {
+			matches = append(matches, el)
+		}
+	}
+	return matches, nil
+}
+func (r *shareResource) getAllResources(ctx context.Context, name string) ([]api.Resource, error) {
+	resources, err := searchResources(ctx, r.client.Client, &getResourcesOptions{FilterSearch: name})
+	if err != nil {
+		return nil, err
+	}
+	// The search also matches resources whose name only contains the search term.
+	matches := make([]api.Resource, 0)
+	for _, el := range resources {
+		if el.Name == name {
+			matches = append(matches, el)
+		}
+	}
+	return matches, nil
 }
 func (r *shareResource) getAllGroups(ctx context.Context) ([]api.Group, error) {
 	return r.client.Client.GetGroups(ctx, &api.GetGroupsOptions{})
@@ -199,11 +486,131 @@ func (r *shareResource) getAllGroups(ctx context.Context) ([]api.Group, error) {
 func (r *shareResource) getAllUsers(ctx context.Context) ([]api.User, error) {
 	return r.client.Client.GetUsers(ctx, &api.GetUsersOptions{})
 }
-func (r *shareResource) getPermissionEntry(ctx context.Context, folderName string, shareTargetType string, shareTargetValue string) (*api.Permission, error) {
-	folders, err := r.getAllFolders(ctx, folderName)
+
+// shareID builds the ID of a share from the IDs of the shared object and the share target.
+func shareID(objectID string, aroID string) string {
+	return objectID + "/" + aroID
+}
+
+// parseShareID splits the ID of a share into the IDs of the shared object and
+// the share target. It returns empty strings for IDs of unknown format.
+func parseShareID(id string) (string, string) {
+	objectID, aroID, found := strings.Cut(id, "/")
+	if !found || objectID == "" || aroID == "" {
+		return "", ""
+	}
+	return objectID, aroID
+}
+
+// findPermission returns the permission with the given ID, or if there is
+// none, the permission of the given user or group.
+func findPermission(permissions []api.Permission, permissionID string, aro string, aroID string) *api.Permission {
+	if permissionID != "" {
+		for i := range permissions {
+			if permissions[i].ID == permissionID {
+				return &permissions[i]
+			}
+		}
+	}
+	if aroID != "" {
+		for i := range permissions {
+			if permissions[i].ARO == aro && permissions[i].AROForeignKey == aroID {
+				return &permissions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// getObjectPermissions returns the permissions of the folder or resource with the given ID.
+func (r *shareResource) getObjectPermissions(ctx context.Context, shareType string, objectID string) ([]api.Permission, error) {
+	urlPath := "/folders/" + objectID + ".json"
+	var opts interface{} = &api.GetFolderOptions{ContainPermissions: true}
+	if shareType == shareTypeResource {
+		urlPath = "/permissions/resource/" + objectID + ".json"
+		opts = nil
+	}
+	res, msg, err := r.client.Client.DoCustomRequestAndReturnRawResponse(ctx, "GET", urlPath, "v2", nil, opts)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%s %s: %w", strings.ToLower(shareType), objectID, errShareObjectNotFound)
+		}
+		return nil, errors.New(fmt.Sprintf("failed to lookup permissions of %s: %s, err: %v", strings.ToLower(shareType), objectID, err.Error()))
+	}
+
+	if shareType == shareTypeResource {
+		var permissions []api.Permission
+		if err := json.Unmarshal(msg.Body, &permissions); err != nil {
+			return nil, err
+		}
+		return permissions, nil
+	}
+	var folder api.Folder
+	if err := json.Unmarshal(msg.Body, &folder); err != nil {
+		return nil, err
+	}
+	return folder.Permissions, nil
+}
+
+// errShareObjectNotFound is returned when the shared folder or resource no longer exists.
+var errShareObjectNotFound = errors.New("shared object not found")
+
+// getSharedPermissions returns the permissions of the referenced folder or resource,
+// or of all folders or resources of the given name.
+func (r *shareResource) getSharedPermissions(ctx context.Context, data sharesResourceData) ([]api.Permission, error) {
+	if objectID, _ := parseShareID(data.ID.ValueString()); objectID != "" {
+		return r.getObjectPermissions(ctx, data.Type.ValueString(), objectID)
+	}
+	if !data.ResourceID.IsNull() {
+		return r.getObjectPermissions(ctx, shareTypeResource, data.ResourceID.ValueString())
+	}
+	if !data.FolderID.IsNull() {
+		return r.getObjectPermissions(ctx, shareTypeFolder, data.FolderID.ValueString())
+	}
+
+	name := data.Name.ValueString()
+	permissions := make([]api.Permission, 0)
+	if data.Type.ValueString() == shareTypeResource {
+		resources, err := r.getAllResources(ctx, name)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("failed to lookup resource of: %s, err: %v", name, err.Error()))
+		}
+		for _, el := range resources {
+			pems, err := r.getObjectPermissions(ctx, shareTypeResource, el.ID)
+			if err != nil {
+				return nil, err
+			}
+			permissions = append(permissions, pems...)
+		}
+		return permissions, nil
+	}
+
+	folders, err := r.getAllFolders(ctx, name)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to lookup folder of: %s, err: %v", name, err.Error()))
+	}
+	for _, el := range folders {
+		permissions = append(permissions, el.Permissions...)
+	}
+	return permissions, nil
+}
+
+// getPermissionEntry returns the permission granted by the share, or nil if it does not exist.
+func (r *shareResource) getPermissionEntry(ctx context.Context, data sharesResourceData) (*api.Permission, error) {
+	permissions, err := r.getSharedPermissions(ctx, data)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("failed to lookup folder of: %s, err: %v", folderName, err.Error()))
+		return nil, err
+	}
+	shareTargetType := data.ShareTargetType.ValueString()
+	_, aroID := parseShareID(data.ID.ValueString())
+	if !data.AROID.IsNull() {
+		aroID = data.AROID.ValueString()
 	}
+	if !data.PermissionID.IsNull() || aroID != "" {
+		return findPermission(permissions, data.PermissionID.ValueString(), shareTargetType, aroID), nil
+	}
+
+	shareTargetValue := data.ShareTargetValue.ValueString()
 	groups, err := r.getAllGroups(ctx)
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("failed to fetch groups, err: %v", err.Error()))
@@ -212,22 +619,18 @@ func (r *shareResource) getPermissionEntry(ctx context.Context, folderName strin
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("failed to fetch users, err: %v", err.Error()))
 	}
-	for _, el := range folders {
-		if el.Personal == false {
-			for _, pel := range el.Permissions {
-				if pel.ACO == "Folder" && pel.ARO == shareTargetType {
-					if pel.ARO == "User" {
-						for _, uel := range users {
-							if uel.Username == shareTargetValue && uel.ID == pel.AROForeignKey {
-								return &pel, nil
-							}
-						}
-					} else { // aro=Group
-						for _, gel := range groups {
-							if gel.Name == shareTargetValue && gel.ID == pel.AROForeignKey {
-								return &pel, nil
-							}
-						}
+	for _, pel := range permissions {
+		if pel.ARO == shareTargetType {
+			if pel.ARO == "User" {
+				for _, uel := range users {
+					if uel.Username == shareTargetValue && uel.ID == pel.AROForeignKey {
+						return &pel, nil
+					}
+				}
+			} else { // aro=Group
+				for _, gel := range groups {
+					if gel.Name == shareTargetValue && gel.ID == pel.AROForeignKey {
+						return &pel, nil
 					}
 				}
 			}
@@ -236,43 +639,58 @@ func (r *shareResource) getPermissionEntry(ctx context.Context, folderName strin
 	return nil, nil
 }
 
-func (r *shareResource) setPermission(ctx context.Context, data sharesResourceData) error {
-	pemTypeInt := -1
-	switch data.SharePermission.ValueString() {
-	case "-1":
-		pemTypeInt = -1
-	case "1":
-		pemTypeInt = 1
-	case "7":
-		pemTypeInt = 7
-	case "15":
-		pemTypeInt = 15
-	default:
-		return errors.New(fmt.Sprintf("invalid share permission type, expected one of: -1,1,7,15, got input: %s", data.SharePermission.ValueString()))
+// getShareObjectID looks up the ID of the folder or resource to share.
+func (r *shareResource) getShareObjectID(ctx context.Context, data sharesResourceData) (string, error) {
+	if objectID, _ := parseShareID(data.ID.ValueString()); objectID != "" {
+		return objectID, nil
+	}
+	if !data.FolderID.IsNull() {
+		return data.FolderID.ValueString(), nil
+	}
+	if !data.ResourceID.IsNull() {
+		return data.ResourceID.ValueString(), nil
+	}
+	if data.Type.ValueString() == shareTypeResource {
+		resources, err := r.getAllResources(ctx, data.Name.ValueString())
+		if err != nil {
+			return "", errors.New(fmt.Sprintf("failed to find resource of name: %s, err: %v", data.Name.ValueString(), err.Error()))
+		}
+		if len(resources) < 1 {
+			return "", errors.New(fmt.Sprintf("failed to find any resource of name: %s", data.Name.ValueString()))
+		}
+		if len(resources) > 1 {
+			return "", errors.New(fmt.Sprintf("found %d resources of name: %s, use resource_id instead", len(resources), data.Name.ValueString()))
+		}
+		return resources[0].ID, nil
 	}
 
 	folders, err := r.getAllFolders(ctx, data.Name.ValueString())
 	if err != nil {
-		return errors.New(fmt.Sprintf("failed to find folder of name: %s, err: %v", data.Name.ValueString(), err.Error()))
+		return "", errors.New(fmt.Sprintf("failed to find folder of name: %s, err: %v", data.Name.ValueString(), err.Error()))
 	}
 	if len(folders) < 1 {
-		return errors.New(fmt.Sprintf("failed to find any folder of name: %s", data.Name.ValueString()))
+		return "", errors.New(fmt.Sprintf("failed to find any folder of name: %s", data.Name.ValueString()))
 	}
-	var folder api.Folder
-	for _, el := range folders {
-		// TODO: do we want to apply requested permission to all found folders?
-		folder = el
-		break
+	if len(folders) > 1 {
+		return "", errors.New(fmt.Sprintf("found %d folders of name: %s, use folder_id instead", len(folders), data.Name.ValueString()))
 	}
-	if len(folder.ID) < 0 {
-		return errors.New(fmt.Sprintf("failed to find folder of name: %s", data.Name.ValueString()))
+	return folders[0].ID, nil
+}
+
+// getShareTargetID looks up the ID of the user or group to share with.
+func (r *shareResource) getShareTargetID(ctx context.Context, data sharesResourceData) (string, error) {
+	if !data.AROID.IsNull() {
+		return data.AROID.ValueString(), nil
+	}
+	if _, aroID := parseShareID(data.ID.ValueString()); aroID != "" {
+		return aroID, nil
 	}
 
 	aroID := ""
 	if data.ShareTargetType.ValueString() == "Group" {
 		groups, err := r.getAllGroups(ctx)
 		if err != nil {
-			return errors.New(fmt.Sprintf("failed to fetch groups, err: %v", err.Error()))
+			return "", errors.New(fmt.Sprintf("failed to fetch groups, err: %v", err.Error()))
 		}
 		for _, el := range groups {
 			if el.Name == data.ShareTargetValue.ValueString() {
@@ -283,7 +701,7 @@ func (r *shareResource) setPermission(ctx context.Context, data sharesResourceDa
 	} else {
 		users, err := r.getAllUsers(ctx)
 		if err != nil {
-			return errors.New(fmt.Sprintf("failed to fetch users, err: %v", err.Error()))
+			return "", errors.New(fmt.Sprintf("failed to fetch users, err: %v", err.Error()))
 		}
 		for _, el := range users {
 			if el.Username == data.ShareTargetValue.ValueString() {
@@ -293,16 +711,150 @@ func (r *shareResource) setPermission(ctx context.Context, data sharesResourceDa
 		}
 	}
 	if aroID == "" {
-		return errors.New(fmt.Sprintf("failed to find share target, type: %s, value: %s", data.ShareTargetType.ValueString(), data.ShareTargetValue.ValueString()))
+		return "", errors.New(fmt.Sprintf("failed to find share target, %s", data.targetRef()))
 	}
-	if shareErr := helper.ShareFolder(ctx, r.client.Client, folder.ID, []helper.ShareOperation{
-		{
-			Type:  pemTypeInt,
-			ARO:   data.ShareTargetType.ValueString(),
-			AROID: aroID,
-		},
-	}); shareErr != nil {
-		return errors.New(fmt.Sprintf("Failed to share resource, %s, %s, err: %v", folder.ID, aroID, shareErr.Error()))
+	return aroID, nil
+}
+
+// getShareOperation resolves the folder or resource and the share target of data.
+func (r *shareResource) getShareOperation(ctx context.Context, data sharesResourceData) (string, helper.ShareOperation, error) {
+	pemTypeInt := permissionDelete
+	if data.SharePermission.ValueString() != "-1" {
+		var err error
+		pemTypeInt, err = parsePermissionLevel(data.SharePermission.ValueString())
+		if err != nil {
+			return "", helper.ShareOperation{}, err
+		}
 	}
-	return nil
+
+	objectID, err := r.getShareObjectID(ctx, data)
+	if err != nil {
+		return "", helper.ShareOperation{}, err
+	}
+
+	aroID, err := r.getShareTargetID(ctx, data)
+	if err != nil {
+		return "", helper.ShareOperation{}, err
+	}
+
+	return objectID, helper.ShareOperation{
+		Type:  pemTypeInt,
+		ARO:   data.ShareTargetType.ValueString(),
+		AROID: aroID,
+	}, nil
+}
+
+func (r *shareResource) setPermission(ctx context.Context, data sharesResourceData) (string, helper.ShareOperation, error) {
+	objectID, share, err := r.getShareOperation(ctx, data)
+	if err != nil {
+		return "", share, err
+	}
+	if err := r.checkLockout(ctx, data, objectID, share); err != nil {
+		return "", share, err
+	}
+
+	var shareErr error
+	if data.Type.ValueString() == shareTypeResource {
+		// helper.ShareResource also encrypts the secret for new users and group members.
+		shareErr = helper.ShareResource(ctx, r.client.Client, objectID, []helper.ShareOperation{share})
+	} else {
+		shareErr = helper.ShareFolder(ctx, r.client.Client, objectID, []helper.ShareOperation{share})
+	}
+	if shareErr != nil {
+		return "", share, errors.New(fmt.Sprintf("Failed to share resource, %s, %s, err: %v", objectID, share.AROID, shareErr.Error()))
+	}
+	return objectID, share, nil
+}
+
+// updatePermission changes the type of the permission row tracked in state,
+// or deletes it if share_permission is -1. Falls back to setPermission if the
+// row no longer exists.
+func (r *shareResource) updatePermission(ctx context.Context, data sharesResourceData) (string, helper.ShareOperation, error) {
+	pem, err := r.getPermissionEntry(ctx, data)
+	if err != nil {
+		return "", helper.ShareOperation{}, err
+	}
+	if pem == nil || pem.ID != data.PermissionID.ValueString() {
+		data.PermissionID = types.StringNull()
+		return r.setPermission(ctx, data)
+	}
+
+	share := helper.ShareOperation{Type: permissionDelete, ARO: pem.ARO, AROID: pem.AROForeignKey}
+	if data.SharePermission.ValueString() != "-1" {
+		share.Type, err = parsePermissionLevel(data.SharePermission.ValueString())
+		if err != nil {
+			return "", share, err
+		}
+	}
+
+	if share.Type == pem.Type {
+		// Already up to date, e.g. the change was made outside of terraform.
+		return pem.ACOForeignKey, share, nil
+	}
+	change := api.Permission{
+		ID:            pem.ID,
+		ARO:           pem.ARO,
+		AROForeignKey: pem.AROForeignKey,
+		ACO:           pem.ACO,
+		ACOForeignKey: pem.ACOForeignKey,
+		Type:          share.Type,
+	}
+	if share.Type == permissionDelete {
+		change.Delete = true
+		change.Type = pem.Type
+	}
+	if err := r.checkLockout(ctx, data, pem.ACOForeignKey, share); err != nil {
+		return "", share, err
+	}
+	if err := r.savePermission(ctx, data, change); err != nil {
+		return "", share, errors.New(fmt.Sprintf("Failed to update permission: %s, err: %v", pem.ID, err.Error()))
+	}
+	return pem.ACOForeignKey, share, nil
+}
+
+// checkLockout refuses share if it would lock the acting user out of the shared folder or resource.
+func (r *shareResource) checkLockout(ctx context.Context, data sharesResourceData, objectID string, share helper.ShareOperation) error {
+	permissions, err := r.getObjectPermissions(ctx, data.Type.ValueString(), objectID)
+	if err != nil {
+		return err
+	}
+	return r.client.checkShareLockout(ctx, permissions, []helper.ShareOperation{share})
+}
+
+// savePermission sends a change of an existing permission row to the server.
+// Changing the type of an existing row does not require re-encrypting secrets.
+func (r *shareResource) savePermission(ctx context.Context, data sharesResourceData, pem api.Permission) error {
+	if data.Type.ValueString() == shareTypeResource {
+		return r.client.Client.ShareResource(ctx, pem.ACOForeignKey, api.ResourceShareRequest{Permissions: []api.Permission{pem}})
+	}
+	return r.client.Client.ShareFolder(ctx, pem.ACOForeignKey, []api.Permission{pem})
+}
+
+// shareTypeDefault defaults the type attribute to resource if resource_id is
+// configured and to folder otherwise.
+type shareTypeDefault struct{}
+
+// Description returns a plain text description of the modifier's behavior.
+func (m shareTypeDefault) Description(_ context.Context) string {
+	return "Defaults to resource if resource_id is set, folder otherwise."
+}
+
+// MarkdownDescription returns a markdown formatted description of the modifier's behavior.
+func (m shareTypeDefault) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// PlanModifyString implements the plan modification logic.
+func (m shareTypeDefault) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+
+	var resourceID types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("resource_id"), &resourceID)...)
+	if !resourceID.IsNull() {
+		resp.PlanValue = types.StringValue(shareTypeResource)
+		return
+	}
+	resp.PlanValue = types.StringValue(shareTypeFolder)
 }
@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShareID(t *testing.T) {
+	objectID, aroID := parseShareID(shareID("f1", "u1"))
+	assert.Equal(t, "f1", objectID)
+	assert.Equal(t, "u1", aroID)
+
+	objectID, aroID = parseShareID("")
+	assert.Empty(t, objectID)
+	assert.Empty(t, aroID)
+}
+
+func TestFindPermission(t *testing.T) {
+	permissions := []api.Permission{
+		{ID: "p1", ARO: "User", AROForeignKey: "u1", Type: 15},
+		{ID: "p2", ARO: "Group", AROForeignKey: "u1", Type: 1},
+	}
+
+	assert.Equal(t, "p2", findPermission(permissions, "p2", "User", "u1").ID)
+	// A permission re-created outside of terraform is found by its target.
+	assert.Equal(t, "p1", findPermission(permissions, "p3", "User", "u1").ID)
+	assert.Nil(t, findPermission(permissions, "p3", "User", "u2"))
+}
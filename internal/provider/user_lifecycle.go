@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+)
+
+// userDetails is a user including the attributes api.User does not expose.
+type userDetails struct {
+	api.User
+	IsMFAEnabled *bool   `json:"is_mfa_enabled,omitempty"`
+	Disabled     *string `json:"disabled,omitempty"`
+}
+
+// newAPIUser returns the request body to create or update a user, with the
+// role resolved from its name. A null or unknown role is left to the server.
+func newAPIUser(ctx context.Context, client *api.Client, username string, firstName string, lastName string, role types.String) (api.User, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	user := api.User{
+		Username: username,
+		Profile: &api.Profile{
+			FirstName: firstName,
+			LastName:  lastName,
+		},
+	}
+	if !role.IsNull() && !role.IsUnknown() {
+		roleID, err := getRoleID(ctx, client, role.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("role"), "Unknown role", err.Error())
+			return user, diags
+		}
+		user.RoleID = roleID
+	}
+	return user, diags
+}
+
+// planRoleID resolves the planned role to role_id, so role changes show up in
+// the plan and unknown roles fail before anything is applied.
+func planRoleID(ctx context.Context, client *api.Client, role types.String, resp *resource.ModifyPlanResponse) {
+	if role.IsNull() || role.IsUnknown() {
+		return
+	}
+	roleID, err := getRoleID(ctx, client, role.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("role"), "Unknown role", err.Error())
+		return
+	}
+	if roleID == role.ValueString() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("role"),
+			"Deprecated role ID",
+			"Setting role to the ID of a role is deprecated and will be removed in a future version. Use the name of the role instead, e.g. admin or user.",
+		)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("role_id"), roleID)...)
+}
+
+// getUserDetails returns the user with its MFA and suspension status, or nil
+// if it does not exist.
+func getUserDetails(ctx context.Context, client *api.Client, userID string) (*userDetails, error) {
+	res, msg, err := client.DoCustomRequestAndReturnRawResponse(ctx, "GET", "/users/"+userID+".json", "v2", nil, nil)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var user userDetails
+	if err := json.Unmarshal(msg.Body, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// userLookupError describes why getUserDetails returned no user.
+func userLookupError(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "The user does not exist."
+}
+
+// setUserDisabled suspends or reactivates the user. Suspension requires Passbolt 4.x.
+func setUserDisabled(ctx context.Context, client *api.Client, userID string, disabled bool) error {
+	var value *string
+	if disabled {
+		now := time.Now().UTC().Format(time.RFC3339)
+		value = &now
+	}
+	body := map[string]*string{"disabled": value}
+	_, err := client.DoCustomRequest(ctx, "PUT", "/users/"+userID+".json", "v2", body, nil)
+	return err
+}
+
+// resendInvite sends the setup email to a user who has not completed the setup yet.
+func resendInvite(ctx context.Context, client *api.Client, username string) error {
+	body := map[string]string{"username": username}
+	_, err := client.DoCustomRequest(ctx, "POST", "/users/recover.json", "v2", body, nil)
+	return err
+}
+
+// setUserStatus sets the computed status attributes of model from the user.
+func setUserStatus(model *usersModel, user *userDetails) {
+	model.Active = types.BoolValue(user.Active)
+	model.LastLoggedIn = types.StringNull()
+	if user.LastLoggedIn != "" {
+		model.LastLoggedIn = types.StringValue(user.LastLoggedIn)
+	}
+	model.IsMFAEnabled = types.BoolValue(user.IsMFAEnabled != nil && *user.IsMFAEnabled)
+	model.Disabled = types.BoolValue(isUserDisabled(user))
+}
+
+// isUserDisabled reports whether the user is suspended.
+func isUserDisabled(user *userDetails) bool {
+	return user.Disabled != nil && *user.Disabled != ""
+}
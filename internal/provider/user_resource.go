@@ -3,19 +3,27 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/passbolt/go-passbolt/api"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &userResource{}
-	_ resource.ResourceWithConfigure = &userResource{}
+	_ resource.Resource                = &userResource{}
+	_ resource.ResourceWithConfigure   = &userResource{}
+	_ resource.ResourceWithModifyPlan  = &userResource{}
+	_ resource.ResourceWithImportState = &userResource{}
 )
 
 // NewUserResource is a helper function to simplify the provider implementation.
@@ -32,11 +40,34 @@ type userResource struct {
 type usersModel struct {
 	ID        types.String `tfsdk:"id"`
 	Role      types.String `tfsdk:"role"`
+	RoleID    types.String `tfsdk:"role_id"`
 	UserName  types.String `tfsdk:"username"`
 	FirstName types.String `tfsdk:"firstname"`
 	LastName  types.String `tfsdk:"lastname"`
+
+	Active              types.Bool   `tfsdk:"active"`
+	LastLoggedIn        types.String `tfsdk:"last_logged_in"`
+	IsMFAEnabled        types.Bool   `tfsdk:"is_mfa_enabled"`
+	Disabled            types.Bool   `tfsdk:"disabled"`
+	ResendInviteTrigger types.String `tfsdk:"resend_invite_trigger"`
+	OnConflict          types.String `tfsdk:"on_conflict"`
+	OnUsernameChange    types.String `tfsdk:"on_username_change"`
+
+	TransferOwnershipTo *ownershipTransferModel `tfsdk:"transfer_ownership_to"`
 }
 
+// Values of the on_conflict attribute.
+const (
+	userOnConflictError = "error"
+	userOnConflictAdopt = "adopt"
+)
+
+// Values of the on_username_change attribute.
+const (
+	userOnUsernameChangeUpdate  = "update"
+	userOnUsernameChangeReplace = "replace"
+)
+
 // Configure adds the provider configured client to the resource.
 func (r *userResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
@@ -71,15 +102,26 @@ func (r *userResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:    true,
 			},
 			"role": schema.StringAttribute{
-				Description: "The user role.",
+				Description: "The name of the user role, either: admin, user or the name of a custom role. Defaults to the server default, usually user. Role IDs are accepted as well, but deprecated.",
 				Computed:    true,
 				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_id": schema.StringAttribute{
+				Description: "The ID of the user role.",
+				Computed:    true,
 			},
 			"username": schema.StringAttribute{
-				Description: "The user name. This needs to be a valid email. User will be replaced upon username change.",
+				Description: "The user name. This needs to be a valid email. Changes are applied in place or by replacing the user, see `on_username_change`.",
 				Required:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.RequiresReplaceIf(
+						usernameChangeReplaces,
+						"Replaces the user if on_username_change is replace.",
+						"Replaces the user if `on_username_change` is `replace`.",
+					),
 				},
 			},
 			"firstname": schema.StringAttribute{
@@ -90,10 +132,100 @@ func (r *userResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Description: "The last name of the user.",
 				Required:    true,
 			},
+			"active": schema.BoolAttribute{
+				Description: "Whether the user has completed the account setup.",
+				Computed:    true,
+			},
+			"last_logged_in": schema.StringAttribute{
+				Description: "The time of the last login of the user, if any.",
+				Computed:    true,
+			},
+			"is_mfa_enabled": schema.BoolAttribute{
+				Description: "Whether the user has enabled multi-factor authentication.",
+				Computed:    true,
+			},
+			"disabled": schema.BoolAttribute{
+				Description: "Whether the user is suspended. Suspended users cannot log in, but keep their resources, folders and group memberships. Requires Passbolt 4.x.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"resend_invite_trigger": schema.StringAttribute{
+				Description: "An arbitrary value, changing it re-sends the setup email to a user who has not completed the account setup yet.",
+				Optional:    true,
+			},
+			"on_conflict": schema.StringAttribute{
+				Description: "What to do if a user of the same username already exists on create, either: error, adopt. Adopted users are managed, and deleted, like users created by terraform. Defaults to error.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(userOnConflictError),
+				Validators: []validator.String{
+					stringOneOf(userOnConflictError, userOnConflictAdopt),
+				},
+			},
+			"on_username_change": schema.StringAttribute{
+				Description: "How to apply a username change, either: update, replace. Update changes the username in place and fails if the server does not allow it, replace deletes the user and creates a new one. Defaults to update.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(userOnUsernameChangeUpdate),
+				Validators: []validator.String{
+					stringOneOf(userOnUsernameChangeUpdate, userOnUsernameChangeReplace),
+				},
+			},
+			"transfer_ownership_to": transferOwnershipSchema("user"),
 		},
 	}
 }
 
+// ModifyPlan resolves the role name, so the plan fails for unknown roles and
+// shows the role ID. On destroy it warns about the items solely owned by the user.
+func (r *userResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if r.client == nil {
+		return
+	}
+	if req.Plan.Raw.IsNull() {
+		if req.State.Raw.IsNull() {
+			return
+		}
+		var state usersModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(planOwnedItems(ctx, r.client.Client, "User", state.ID.ValueString(), state.UserName.ValueString(), state.TransferOwnershipTo, "")...)
+		return
+	}
+
+	if !req.State.Raw.IsNull() && resp.RequiresReplace.Contains(path.Root("username")) {
+		var state usersModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(planOwnedItems(ctx, r.client.Client, "User", state.ID.ValueString(), state.UserName.ValueString(), state.TransferOwnershipTo, "Changing the username replaces the user. ")...)
+	}
+
+	var plan usersModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Catch a group target for a user who manages groups before it is stored
+	// in state, as it can no longer be changed once the user is destroyed.
+	if target := plan.TransferOwnershipTo; !req.State.Raw.IsNull() && target != nil && target.Type.ValueString() == "Group" {
+		items, err := getOwnedItems(ctx, r.client.Client, "User", plan.ID.ValueString())
+		if err == nil {
+			if err := checkTransferTarget(items, *target); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("transfer_ownership_to"), "Invalid ownership transfer target", err.Error())
+				return
+			}
+		}
+	}
+
+	planRoleID(ctx, r.client.Client, plan.Role, resp)
+}
+
 // Create a new resource.
 func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -105,16 +237,45 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	// Generate API request body from plan
-	var user = api.User{
-		Username: plan.UserName.ValueString(),
-		Profile: &api.Profile{
-			FirstName: plan.FirstName.ValueString(),
-			LastName:  plan.LastName.ValueString(),
-		},
-		RoleID: plan.Role.ValueString(),
+	user, diags := newAPIUser(ctx, r.client.Client, plan.UserName.ValueString(), plan.FirstName.ValueString(), plan.LastName.ValueString(), plan.Role)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, err := r.client.Client.GetUsers(ctx, &api.GetUsersOptions{FilterSearch: user.Username})
+	if err != nil {
+		resp.Diagnostics.AddError("failed to get users", err.Error())
+		return
+	}
+	existing := findUserByUsername(users, user.Username)
+	if existing != nil && plan.OnConflict.ValueString() != userOnConflictAdopt {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("user of name: %s already exists", user.Username),
+			fmt.Sprintf("The user %s (ID: %s) already exists. Set on_conflict = \"adopt\" to manage it with terraform, or import it by its ID or username.", existing.Username, existing.ID),
+		)
+		return
+	}
+
+	// Suspension is not part of the creation request, adopted users may be suspended already.
+	changeSuspension := plan.Disabled.ValueBool()
+	if existing != nil {
+		current, err := getUserDetails(ctx, r.client.Client, existing.ID)
+		if err != nil || current == nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Cannot get user: %s", existing.ID), userLookupError(err))
+			return
+		}
+		changeSuspension = isUserDisabled(current) != plan.Disabled.ValueBool()
 	}
 
-	cUser, errCreate := r.client.Client.CreateUser(r.client.Context, user)
+	var cUser *api.User
+	var errCreate error
+	if existing != nil {
+		tflog.Info(ctx, "Adopting existing user", map[string]interface{}{"userId": existing.ID, "username": existing.Username})
+		cUser, errCreate = r.client.Client.UpdateUser(ctx, existing.ID, user)
+	} else {
+		cUser, errCreate = r.client.Client.CreateUser(ctx, user)
+	}
 	if errCreate != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to create user of name: %s", user.Username),
@@ -125,7 +286,27 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 
 	// Map response body to schema and populate Computed attribute values
 	plan.ID = types.StringValue(cUser.ID)
-	plan.Role = types.StringValue(cUser.Role.ID)
+	setUserRole(&plan, cUser)
+
+	if changeSuspension {
+		err := setUserDisabled(ctx, r.client.Client, cUser.ID, plan.Disabled.ValueBool())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to change the suspension of user of name: %s", user.Username),
+				err.Error(),
+			)
+			// Keep the user in state (tainted), it exists now.
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), cUser.ID)...)
+			return
+		}
+	}
+
+	details, err := getUserDetails(ctx, r.client.Client, cUser.ID)
+	if err != nil || details == nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Cannot get user: %s", cUser.ID), userLookupError(err))
+		details = &userDetails{User: *cUser}
+	}
+	setUserStatus(&plan, details)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -145,7 +326,7 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	user, err := r.client.Client.GetUser(r.client.Context, state.ID.ValueString())
+	user, err := getUserDetails(ctx, r.client.Client, state.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("Cannot get user: %s", state.ID.ValueString()),
@@ -153,9 +334,15 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		)
 		return
 	}
+	if user == nil || user.Deleted {
+		// The user was deleted outside of terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
 	state.ID = types.StringValue(user.ID)
-	state.Role = types.StringValue(user.Role.ID)
+	setUserRole(&state, &user.User)
+	setUserStatus(&state, user)
 	state.UserName = types.StringValue(user.Username)
 	state.FirstName = types.StringValue(user.Profile.FirstName)
 	state.LastName = types.StringValue(user.Profile.LastName)
@@ -172,25 +359,22 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	// Retrieve values from plan
 	var plan usersModel
-	diags := req.Config.Get(ctx, &plan)
+	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Generate API request body from plan
-	var user = api.User{
-		Username: plan.UserName.ValueString(),
-		Profile: &api.Profile{
-			FirstName: plan.FirstName.ValueString(),
-			LastName:  plan.LastName.ValueString(),
-		},
-		RoleID: plan.Role.ValueString(),
+	user, diags := newAPIUser(ctx, r.client.Client, plan.UserName.ValueString(), plan.FirstName.ValueString(), plan.LastName.ValueString(), plan.Role)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	var state usersModel
 	req.State.Get(ctx, &state)
-	cUser, err := r.client.Client.UpdateUser(r.client.Context, state.ID.ValueString(), user)
+	cUser, err := r.client.Client.UpdateUser(ctx, state.ID.ValueString(), user)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to update user of name: %s", user.Username),
@@ -199,12 +383,63 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
+	if !strings.EqualFold(cUser.Username, user.Username) {
+		// The other changes were applied, keep them in state so only the
+		// username is planned again.
+		applied := state
+		applied.Role = plan.Role
+		setUserRole(&applied, cUser)
+		applied.UserName = types.StringValue(cUser.Username)
+		applied.FirstName = types.StringValue(cUser.Profile.FirstName)
+		applied.LastName = types.StringValue(cUser.Profile.LastName)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &applied)...)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("username"),
+			fmt.Sprintf("failed to change the username of user: %s", state.UserName.ValueString()),
+			"The server did not apply the new username, it may not allow username changes. Set on_username_change = \"replace\" to replace the user instead.",
+		)
+		return
+	}
+
 	plan.ID = types.StringValue(cUser.ID)
-	plan.Role = types.StringValue(cUser.Role.ID)
+	setUserRole(&plan, cUser)
 	plan.UserName = types.StringValue(cUser.Username)
 	plan.FirstName = types.StringValue(cUser.Profile.FirstName)
 	plan.LastName = types.StringValue(cUser.Profile.LastName)
 
+	if !plan.Disabled.Equal(state.Disabled) {
+		err = setUserDisabled(ctx, r.client.Client, cUser.ID, plan.Disabled.ValueBool())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to change the suspension of user of name: %s", user.Username),
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	details, err := getUserDetails(ctx, r.client.Client, cUser.ID)
+	if err != nil || details == nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Cannot get user: %s", cUser.ID), userLookupError(err))
+		return
+	}
+	setUserStatus(&plan, details)
+
+	if !plan.ResendInviteTrigger.Equal(state.ResendInviteTrigger) && !plan.ResendInviteTrigger.IsNull() {
+		if details.Active {
+			resp.Diagnostics.AddWarning(
+				fmt.Sprintf("Invite not re-sent to user: %s", user.Username),
+				"The user has already completed the account setup.",
+			)
+		} else if err := resendInvite(ctx, r.client.Client, user.Username); err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("failed to re-send the invite to user of name: %s", user.Username),
+				err.Error(),
+			)
+			return
+		}
+	}
+
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -223,7 +458,16 @@ func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	err := r.client.Client.DeleteUser(r.client.Context, state.ID.ValueString())
+	err := transferOwnedItems(ctx, r.client.Client, "User", state.ID.ValueString(), state.TransferOwnershipTo)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("failed to transfer ownership of user with ID: %s", state.ID.ValueString()),
+			err.Error(),
+		)
+		return
+	}
+
+	err = r.client.Client.DeleteUser(ctx, state.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("failed to delete user with ID: %s", state.ID.ValueString()),
@@ -237,3 +481,87 @@ func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 }
+
+// ImportState imports a user by its ID or username.
+func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	userID := req.ID
+	if strings.Contains(req.ID, "@") {
+		users, err := r.client.Client.GetUsers(ctx, &api.GetUsersOptions{FilterSearch: req.ID})
+		if err != nil {
+			resp.Diagnostics.AddError("failed to get users", err.Error())
+			return
+		}
+		user := findUserByUsername(users, req.ID)
+		if user == nil {
+			resp.Diagnostics.AddError("Cannot import user", fmt.Sprintf("No user found with username: %s", req.ID))
+			return
+		}
+		userID = user.ID
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), userID)...)
+	// Set the defaults, so the import is not followed by an update.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("on_conflict"), userOnConflictError)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("on_username_change"), userOnUsernameChangeUpdate)...)
+}
+
+// getRoleID returns the ID of the role with the given name or, deprecated, ID.
+func getRoleID(ctx context.Context, client *api.Client, ref string) (string, error) {
+	roles, err := client.GetRoles(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get roles: %w", err)
+	}
+	role, err := findRole(roles, ref)
+	if err != nil {
+		return "", err
+	}
+	return role.ID, nil
+}
+
+// findRole returns the role with the given name. Roles referenced by their ID,
+// as before roles could be set by name, are found as well.
+func findRole(roles []api.Role, ref string) (*api.Role, error) {
+	names := make([]string, 0, len(roles))
+	for i := range roles {
+		if roles[i].Name == ref {
+			return &roles[i], nil
+		}
+		names = append(names, roles[i].Name)
+	}
+	for i := range roles {
+		if roles[i].ID == ref {
+			return &roles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no role named %q, available roles: %s", ref, strings.Join(names, ", "))
+}
+
+// findUserByUsername returns the user with the given username, or nil if there is none.
+// Usernames are email addresses and compared case-insensitively.
+func findUserByUsername(users []api.User, username string) *api.User {
+	for i := range users {
+		if strings.EqualFold(users[i].Username, username) {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// setUserRole sets the role attributes of model from the role of the user.
+// role is stored as the role name, unless it is set to the deprecated role ID:
+// a configured value cannot be changed without an inconsistent result error.
+func setUserRole(model *usersModel, user *api.User) {
+	model.RoleID = types.StringValue(user.Role.ID)
+	if model.Role.ValueString() == user.Role.ID {
+		return
+	}
+	model.Role = types.StringValue(user.Role.Name)
+}
+
+// usernameChangeReplaces requires the replacement of the user on username
+// changes if on_username_change is replace.
+func usernameChangeReplaces(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	var onUsernameChange types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("on_username_change"), &onUsernameChange)...)
+	resp.RequiresReplace = onUsernameChange.ValueString() == userOnUsernameChangeReplace
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/passbolt/go-passbolt/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindRole(t *testing.T) {
+	roles := []api.Role{
+		{ID: "r1", Name: "admin"},
+		{ID: "r2", Name: "user"},
+		{ID: "r3", Name: "auditor"},
+	}
+
+	role, err := findRole(roles, "auditor")
+	assert.NoError(t, err)
+	assert.Equal(t, "r3", role.ID)
+
+	// Role IDs are still accepted, but names take precedence.
+	role, err = findRole(roles, "r1")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", role.Name)
+
+	_, err = findRole(roles, "r4")
+	assert.EqualError(t, err, `no role named "r4", available roles: admin, user, auditor`)
+}
+
+func TestFindRoleByUUID(t *testing.T) {
+	roles := []api.Role{
+		{ID: "0d51c3a8-3e4f-4ff4-a1c3-d3c9e6a0b0a1", Name: "admin"},
+		{ID: "b58de6d3-f52c-5080-b79b-a601a647ac85", Name: "user"},
+	}
+
+	role, err := findRole(roles, "b58de6d3-f52c-5080-b79b-a601a647ac85")
+	assert.NoError(t, err)
+	assert.Equal(t, "user", role.Name)
+}
+
+func TestSetUserRole(t *testing.T) {
+	user := &api.User{Role: &api.Role{ID: "r1", Name: "admin"}}
+
+	model := usersModel{Role: types.StringNull()}
+	setUserRole(&model, user)
+	assert.Equal(t, "admin", model.Role.ValueString())
+	assert.Equal(t, "r1", model.RoleID.ValueString())
+
+	// A configured role ID is kept to match the configuration.
+	model = usersModel{Role: types.StringValue("r1")}
+	setUserRole(&model, user)
+	assert.Equal(t, "r1", model.Role.ValueString())
+}
+
+func TestSetUserStatus(t *testing.T) {
+	mfa := true
+	disabled := "2024-05-01T10:00:00+00:00"
+	var model usersModel
+
+	setUserStatus(&model, &userDetails{
+		User:         api.User{Active: true, LastLoggedIn: "2024-04-30T08:00:00+00:00"},
+		IsMFAEnabled: &mfa,
+		Disabled:     &disabled,
+	})
+	assert.True(t, model.Active.ValueBool())
+	assert.True(t, model.IsMFAEnabled.ValueBool())
+	assert.True(t, model.Disabled.ValueBool())
+	assert.Equal(t, "2024-04-30T08:00:00+00:00", model.LastLoggedIn.ValueString())
+
+	// Users who never logged in and servers without MFA or suspension support.
+	setUserStatus(&model, &userDetails{})
+	assert.False(t, model.Active.ValueBool())
+	assert.False(t, model.IsMFAEnabled.ValueBool())
+	assert.False(t, model.Disabled.ValueBool())
+	assert.True(t, model.LastLoggedIn.IsNull())
+}
+
+func TestFindUserByUsername(t *testing.T) {
+	users := []api.User{
+		{ID: "u1", Username: "jane.doe@example.com"},
+		{ID: "u2", Username: "jane.doe@example.org"},
+	}
+
+	// The search filter of the API also matches partial usernames.
+	assert.Equal(t, "u1", findUserByUsername(users, "Jane.Doe@example.com").ID)
+	assert.Nil(t, findUserByUsername(users, "jane@example.com"))
+}
+
+func TestUserLookupError(t *testing.T) {
+	assert.Equal(t, "The user does not exist.", userLookupError(nil))
+	assert.Equal(t, "boom", userLookupError(errors.New("boom")))
+}
+
+func TestNewAPIUser(t *testing.T) {
+	// Without a role nothing is looked up and the server default applies.
+	user, diags := newAPIUser(context.Background(), nil, "ci@example.com", "CI", "Bot", types.StringNull())
+	assert.False(t, diags.HasError())
+	assert.Equal(t, "ci@example.com", user.Username)
+	assert.Equal(t, "Bot", user.Profile.LastName)
+	assert.Empty(t, user.RoleID)
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ validator.String = stringOneOfValidator{}
+)
+
+// stringOneOfValidator validates that a string attribute is one of a fixed set
+// of values, or a non-empty suffix to one of a fixed set of prefixes.
+type stringOneOfValidator struct {
+	values   []string
+	prefixes []string
+}
+
+// stringOneOf returns a validator which ensures the configured value is one of values.
+func stringOneOf(values ...string) stringOneOfValidator {
+	return stringOneOfValidator{values: values}
+}
+
+// stringOneOfOrPrefix returns a validator which ensures the configured value is
+// one of values or starts with one of prefixes followed by at least one character.
+func stringOneOfOrPrefix(prefixes []string, values ...string) stringOneOfValidator {
+	return stringOneOfValidator{values: values, prefixes: prefixes}
+}
+
+// Description describes the validation in plain text formatting.
+func (v stringOneOfValidator) Description(_ context.Context) string {
+	if len(v.prefixes) > 0 {
+		return fmt.Sprintf("value must be one of: %s, or start with one of: %s", strings.Join(v.values, ", "), strings.Join(v.prefixes, ", "))
+	}
+	return fmt.Sprintf("value must be one of: %s", strings.Join(v.values, ", "))
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v stringOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation.
+func (v stringOneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	for _, el := range v.values {
+		if el == value {
+			return
+		}
+	}
+	for _, el := range v.prefixes {
+		if strings.HasPrefix(value, el) && len(value) > len(el) {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid Attribute Value",
+		fmt.Sprintf("Attribute %s %s, got: %s", req.Path, v.Description(ctx), value),
+	)
+}
@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringOneOfOrPrefix(t *testing.T) {
+	v := stringOneOfOrPrefix([]string{folderOnDeleteMoveTo}, folderOnDeleteFailIfNotEmpty, folderOnDeleteMoveToParent)
+
+	for value, valid := range map[string]bool{
+		"fail_if_not_empty":        true,
+		"move_to_parent":           true,
+		"move_to:Platform/Archive": true,
+		"move_to:":                 false,
+		"delete":                   false,
+	} {
+		req := validator.StringRequest{Path: path.Root("on_delete"), ConfigValue: types.StringValue(value)}
+		resp := &validator.StringResponse{}
+		v.ValidateString(context.Background(), req, resp)
+		assert.Equal(t, !valid, resp.Diagnostics.HasError(), value)
+	}
+}